@@ -0,0 +1,345 @@
+package wirex
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressOptions configures the Compress middleware.
+type CompressOptions struct {
+	// MinSize is the size under which responses are sent uncompressed, 1024 bytes by default. Responses
+	// flushed before reaching it are compressed regardless, as they're streamed. HEAD responses are
+	// measured by their Content-Length.
+	MinSize int
+
+	// ContentTypes lists the media types compressed, like "application/json". A type ending in "/*"
+	// matches its whole family, and "application/*+json" matches the types with that suffix. By default,
+	// text, JSON, JavaScript, XML, SVG, NDJSON and event streams are compressed.
+	ContentTypes []string
+
+	// Encodings lists the content codings offered, in order of preference. "zstd", "gzip" and "deflate"
+	// are supported, and all of them are offered by default.
+	Encodings []string
+}
+
+var defaultCompressedTypes = []string{
+	"text/*",
+	"application/json",
+	"application/*+json",
+	"application/javascript",
+	"application/xml",
+	"application/*+xml",
+	"application/x-ndjson",
+	"image/svg+xml",
+}
+
+// encoder is a pooled compressor of a content coding.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoders = map[string]*sync.Pool{
+	"zstd": {New: func() any {
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithWindowSize(1<<20))
+		return enc
+	}},
+	"gzip": {New: func() any {
+		return gzip.NewWriter(nil)
+	}},
+	"deflate": {New: func() any {
+		// The "deflate" content coding is the zlib format, see RFC 9110.
+		return zlib.NewWriter(nil)
+	}},
+}
+
+// Compress returns a middleware that compresses responses with the content coding preferred by both the
+// client, in its Accept-Encoding header, and the server.
+//
+// The response is buffered until it reaches MinSize, to leave small responses alone. Responses that are
+// already encoded, partial, or of a type not listed in ContentTypes are sent as they are. Compressed
+// responses lose their Content-Length, and their ETag is made weak. Flushing works through the
+// compressor, so streamed responses like Server-Sent Events keep streaming.
+//
+// HEAD responses get the headers of the matching GET response, compressed when their Content-Length
+// reaches MinSize. Without a Content-Length, their size is unknown and they're left as they are.
+//
+// Usage Example:
+//
+//	engine.Pre(wirex.Compress(wirex.CompressOptions{}))
+func Compress(opts CompressOptions) Middleware {
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1024
+	}
+	if len(opts.ContentTypes) == 0 {
+		opts.ContentTypes = defaultCompressedTypes
+	}
+	if len(opts.Encodings) == 0 {
+		opts.Encodings = []string{"zstd", "gzip", "deflate"}
+	}
+
+	var supported []string
+	for _, encoding := range opts.Encodings {
+		if _, ok := encoders[encoding]; ok {
+			supported = append(supported, encoding)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cw := &compressWriter{
+				ResponseWriter: w,
+				opts:           &opts,
+				encoding:       negotiateEncoding(r.Header.Get(HeaderAcceptEncoding), supported),
+				head:           r.Method == http.MethodHead,
+			}
+
+			next.ServeHTTP(cw, r)
+			cw.close()
+		})
+	}
+}
+
+// negotiateEncoding returns the supported content coding with the highest weight in the Accept-Encoding
+// header, the first supported one among equals, or the empty string if none is acceptable.
+func negotiateEncoding(header string, supported []string) string {
+	weights := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = parsed
+			}
+		}
+
+		weights[coding] = weight
+	}
+
+	best, bestWeight := "", 0.0
+	for _, coding := range supported {
+		weight, ok := weights[coding]
+		if !ok {
+			weight = weights["*"]
+		}
+
+		if weight > bestWeight {
+			best, bestWeight = coding, weight
+		}
+	}
+
+	return best
+}
+
+// compressWriter buffers the beginning of a response until it can tell whether to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	opts     *CompressOptions
+	encoding string // The negotiated content coding, empty if the client accepts none.
+	head     bool   // Only the headers are sent, as they would be for a GET request.
+
+	status   int
+	buf      []byte
+	decided  bool
+	hijacked bool
+	enc      encoder
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if cw.status == 0 {
+		cw.status = code
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(b)
+		}
+
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.opts.MinSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// Flush compresses the response from now on if it's eligible, whatever its size, and flushes it.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.decide(true) != nil {
+			return
+		}
+	}
+
+	if cw.enc != nil {
+		if cw.enc.Flush() != nil {
+			return
+		}
+	}
+
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Hijack lets the caller take over the connection, leaving the response to it.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(cw.ResponseWriter).Hijack()
+	if err == nil {
+		cw.hijacked = true
+	}
+
+	return conn, brw, err
+}
+
+// Unwrap returns the original ResponseWriter, for http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// decide sends the status and headers, compressing the rest of the response if it's eligible and
+// large, then writes what was buffered so far.
+func (cw *compressWriter) decide(large bool) error {
+	cw.decided = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	header := cw.Header()
+	if cw.compressible() {
+		if !headerHasToken(header, HeaderVary, HeaderAcceptEncoding) {
+			header.Add(HeaderVary, HeaderAcceptEncoding)
+		}
+
+		// A HEAD response has no body to measure, its Content-Length tells the size of the GET one.
+		if cw.head && header.Get(HeaderContentLength) != "" {
+			large = true
+		}
+
+		if large && cw.encoding != "" {
+			header.Del(HeaderContentLength)
+			header.Del(HeaderAcceptRanges)
+			header.Set(HeaderContentEncoding, cw.encoding)
+
+			// The compressed representation isn't byte-for-byte the one the strong ETag was made for.
+			if etag := header.Get(HeaderETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+				header.Set(HeaderETag, "W/"+etag)
+			}
+
+			if !cw.head {
+				cw.enc = encoders[cw.encoding].Get().(encoder)
+				cw.enc.Reset(cw.ResponseWriter)
+			}
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+
+	_, err := cw.Write(buf)
+	return err
+}
+
+// compressible reports whether the response can be compressed, sniffing its content type if it's not set.
+func (cw *compressWriter) compressible() bool {
+	switch {
+	case cw.status < http.StatusOK, cw.status == http.StatusNoContent, cw.status == http.StatusPartialContent,
+		cw.status == http.StatusNotModified:
+		return false
+	}
+
+	header := cw.Header()
+	if header.Get(HeaderContentEncoding) != "" || header.Get(HeaderContentRange) != "" {
+		return false
+	}
+
+	if length, err := strconv.Atoi(header.Get(HeaderContentLength)); err == nil && length < cw.opts.MinSize {
+		return false
+	}
+
+	contentType := header.Get(HeaderContentType)
+	if contentType == "" {
+		if len(cw.buf) == 0 {
+			return false
+		}
+
+		// Sniff it now, net/http would sniff the compressed bytes otherwise.
+		contentType = http.DetectContentType(cw.buf)
+		header.Set(HeaderContentType, contentType)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range cw.opts.ContentTypes {
+		if matchMediaType(pattern, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchMediaType(pattern, mediaType string) bool {
+	pattern = strings.ToLower(pattern)
+
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+
+	if prefix, suffix, ok := strings.Cut(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/") && strings.HasSuffix(mediaType, suffix)
+	}
+
+	return pattern == mediaType
+}
+
+// close ends the response once the handler returned, sending it uncompressed if it stayed small.
+func (cw *compressWriter) close() {
+	if cw.hijacked {
+		return
+	}
+
+	if !cw.decided {
+		cw.decide(false)
+	}
+
+	if cw.enc != nil {
+		cw.enc.Close()
+		encoders[cw.encoding].Put(cw.enc)
+		cw.enc = nil
+	}
+}
@@ -0,0 +1,187 @@
+package wirex
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{"zstd", "gzip", "deflate"}
+
+	assert.Equal(t, "zstd", negotiateEncoding("gzip, deflate, br, zstd", supported))
+	assert.Equal(t, "gzip", negotiateEncoding("gzip;q=1.0, zstd;q=0.5", supported))
+	assert.Equal(t, "deflate", negotiateEncoding("deflate", supported))
+	assert.Equal(t, "gzip", negotiateEncoding("*;q=0.5, zstd;q=0", supported))
+	assert.Equal(t, "", negotiateEncoding("gzip;q=0, identity", supported))
+	assert.Equal(t, "", negotiateEncoding("", supported))
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"id":1,"name":"wirex"},`, 100)
+
+	engine := New()
+	engine.Pre(Compress(CompressOptions{}))
+	engine.Route("/large").Get(func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(HeaderContentType, MIMEApplicationJSON)
+			w.Header().Set(HeaderContentLength, "2400")
+			w.Header().Set(HeaderETag, `"v1"`)
+			io.WriteString(w, large[:1000])
+			io.WriteString(w, large[1000:])
+		})
+	})
+	engine.Route("/small").Get(func(r *http.Request) Writer { return text("small") })
+	engine.Route("/image").Get(func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(HeaderContentType, "image/png")
+			io.WriteString(w, large)
+		})
+	})
+	engine.Route("/encoded").Get(func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(HeaderContentType, MIMEApplicationJSON)
+			w.Header().Set(HeaderContentEncoding, "br")
+			io.WriteString(w, large)
+		})
+	})
+	engine.Route("/partial").Get(func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(HeaderContentType, MIMEApplicationJSON)
+			w.Header().Set(HeaderContentRange, "bytes 0-2399/4800")
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, large)
+		})
+	})
+
+	handler := engine.Handler()
+
+	for encoding, decode := range map[string]func(io.Reader) (io.Reader, error){
+		"gzip":    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		"zstd":    func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	} {
+		recorder := compressGet(handler, "/large", encoding)
+		assert.Equal(t, encoding, recorder.Header().Get(HeaderContentEncoding))
+		assert.Equal(t, HeaderAcceptEncoding, recorder.Header().Get(HeaderVary))
+		assert.Empty(t, recorder.Header().Get(HeaderContentLength))
+		assert.Equal(t, `W/"v1"`, recorder.Header().Get(HeaderETag))
+		assert.Less(t, recorder.Body.Len(), len(large))
+
+		reader, err := decode(recorder.Body)
+		if !assert.NoError(t, err, encoding) {
+			continue
+		}
+		body, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, large, string(body))
+	}
+
+	recorder := compressGet(handler, "/large", "")
+	assert.Empty(t, recorder.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, HeaderAcceptEncoding, recorder.Header().Get(HeaderVary))
+	assert.Equal(t, large, recorder.Body.String())
+
+	recorder = compressGet(handler, "/small", "gzip")
+	assert.Empty(t, recorder.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, "small", recorder.Body.String())
+
+	// HEAD responses carry the headers of the GET ones, for caches that refresh a stored response.
+	req := httptest.NewRequest(http.MethodHead, "/large", nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, "gzip", recorder.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, HeaderAcceptEncoding, recorder.Header().Get(HeaderVary))
+	assert.Empty(t, recorder.Header().Get(HeaderContentLength))
+	assert.Equal(t, `W/"v1"`, recorder.Header().Get(HeaderETag))
+	assert.Empty(t, recorder.Body.String())
+
+	req = httptest.NewRequest(http.MethodHead, "/small", nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Empty(t, recorder.Header().Get(HeaderContentEncoding))
+	assert.Empty(t, recorder.Body.String())
+
+	for _, path := range []string{"/image", "/encoded", "/partial"} {
+		recorder = compressGet(handler, path, "gzip")
+		assert.NotEqual(t, "gzip", recorder.Header().Get(HeaderContentEncoding), path)
+		assert.Empty(t, recorder.Header().Get(HeaderVary), path)
+		assert.Equal(t, large, recorder.Body.String(), path)
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	events := make(chan string)
+
+	engine := New()
+	engine.Pre(Compress(CompressOptions{}))
+	engine.Route("/events").Get(func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(HeaderContentType, MIMETextEventStream)
+			rc := http.NewResponseController(w)
+			for event := range events {
+				io.WriteString(w, "data: "+event+"\n\n")
+				assert.NoError(t, rc.Flush())
+			}
+		})
+	})
+
+	server := httptest.NewServer(engine.Handler())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+
+	done := make(chan *http.Response)
+	go func() {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		done <- resp
+	}()
+
+	events <- "first"
+	resp := <-done
+	defer resp.Body.Close()
+	assert.Equal(t, "gzip", resp.Header.Get(HeaderContentEncoding))
+
+	zr, err := gzip.NewReader(resp.Body)
+	if !assert.NoError(t, err) {
+		close(events)
+		return
+	}
+
+	// Each event is readable before the next one is sent, so nothing is held back by the compressor.
+	lines := bufio.NewReader(zr)
+	line, err := lines.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "data: first\n", line)
+
+	lines.ReadString('\n')
+	events <- "second"
+	line, err = lines.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "data: second\n", line)
+
+	close(events)
+}
+
+func compressGet(handler http.Handler, path, encoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if encoding != "" {
+		req.Header.Set(HeaderAcceptEncoding, encoding)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
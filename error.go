@@ -1,7 +1,7 @@
 package wirex
 
 import (
-	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 )
@@ -11,30 +11,89 @@ type HTTPError interface {
 	error
 }
 
+// DefaultHTTPError is the HTTPError used by WireX, written as a JSON object.
+//
+// The Message is what the client sees, while Err keeps the underlying error for logs, errors.Is and
+// errors.As. When the Engine is in debug mode, the messages of the whole Err chain are added to the
+// response as well.
 type DefaultHTTPError struct {
 	Status  int    `json:"-"`
-	Message string `json:"message"`
+	Message string `json:"message"`           // The message sent to the client.
+	Code    string `json:"code,omitempty"`    // An optional machine-readable error code.
+	Details any    `json:"details,omitempty"` // Optional structured details, like the invalid fields of a request.
+	Err     error  `json:"-"`                 // The underlying error, only sent to the client in debug mode.
 }
 
 var _ HTTPError = &DefaultHTTPError{}
 
 func (e *DefaultHTTPError) WriteResponse(w http.ResponseWriter, r *http.Request) {
-	writeHeader := w.Header()
-	if writeHeader.Get(HeaderContentType) == "" {
-		writeHeader.Set(HeaderContentType, MIMEApplicationJSON)
+	engine := engineFrom(r)
+	if engine != nil && engine.ProblemDetails {
+		problemFrom(e).WriteResponse(w, r)
+		return
 	}
 
-	w.WriteHeader(e.Status)
+	response := struct {
+		*DefaultHTTPError
+		Causes []string `json:"causes,omitempty"`
+	}{DefaultHTTPError: e}
 
-	if err := json.NewEncoder(w).Encode(*e); err != nil {
+	if engine != nil && engine.Debug {
+		for err := e.Err; err != nil; err = errors.Unwrap(err) {
+			response.Causes = append(response.Causes, err.Error())
+		}
+	}
+
+	if err := WriteJSON(w, r, e.Status, MIMEApplicationJSON, response); err != nil {
 		slog.Error("cannot write json to response", "error", err)
 	}
 }
 
 func (s *DefaultHTTPError) Error() string {
+	if s.Err != nil {
+		return s.Err.Error()
+	}
+
 	return s.Message
 }
 
+// Unwrap returns the underlying error.
+func (s *DefaultHTTPError) Unwrap() error {
+	return s.Err
+}
+
+// WithCode sets the machine-readable error code sent to the client.
+func (s *DefaultHTTPError) WithCode(code string) *DefaultHTTPError {
+	s.Code = code
+	return s
+}
+
+// WithDetails sets the structured details sent to the client.
+func (s *DefaultHTTPError) WithDetails(details any) *DefaultHTTPError {
+	s.Details = details
+	return s
+}
+
+// Error returns an HTTPError with the given status, wrapping err.
+//
+// For client errors (4xx) the message of err is sent to the client, as it describes what is wrong with
+// the request. For server errors (5xx) the client gets the status text only, so internal details don't
+// leak; use NewError to send a message of your own.
 func Error(status int, err error) HTTPError {
-	return &DefaultHTTPError{status, err.Error()}
+	message := http.StatusText(status)
+	if status < http.StatusInternalServerError {
+		message = err.Error()
+	}
+
+	return &DefaultHTTPError{Status: status, Message: message, Err: err}
+}
+
+// NewError returns a DefaultHTTPError with the given status and public message, wrapping err,
+// which may be nil.
+//
+// Usage Example:
+//
+//	return wirex.NewError(http.StatusConflict, "The email is already taken", err).WithCode("email_taken")
+func NewError(status int, message string, err error) *DefaultHTTPError {
+	return &DefaultHTTPError{Status: status, Message: message, Err: err}
 }
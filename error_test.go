@@ -0,0 +1,64 @@
+package wirex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorUnwrap(t *testing.T) {
+	err := Error(http.StatusNotFound, fmt.Errorf("user 42: %w", fs.ErrNotExist))
+
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.Equal(t, "user 42: file does not exist", err.Error())
+
+	var httpErr *DefaultHTTPError
+	assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.Status)
+}
+
+func TestErrorResponse(t *testing.T) {
+	cause := fmt.Errorf("query users: %w", errors.New("connection refused"))
+
+	body := errorResponse(t, Error(http.StatusInternalServerError, cause), false)
+	assert.Equal(t, map[string]any{"message": "Internal Server Error"}, body)
+
+	body = errorResponse(t, Error(http.StatusBadRequest, errors.New("name is required")), false)
+	assert.Equal(t, map[string]any{"message": "name is required"}, body)
+
+	err := NewError(http.StatusConflict, "The email is already taken", cause).
+		WithCode("email_taken").
+		WithDetails(map[string]string{"field": "email"})
+
+	body = errorResponse(t, err, false)
+	assert.Equal(t, map[string]any{
+		"message": "The email is already taken",
+		"code":    "email_taken",
+		"details": map[string]any{"field": "email"},
+	}, body)
+
+	body = errorResponse(t, err, true)
+	assert.Equal(t, []any{"query users: connection refused", "connection refused"}, body["causes"])
+}
+
+func errorResponse(t *testing.T, err HTTPError, debug bool) map[string]any {
+	engine := New()
+	engine.Debug = debug
+	engine.Route("/").Get(func(r *http.Request) Writer { return err })
+
+	recorder := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var body map[string]any
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("Could not decode the error response: %v", err)
+	}
+
+	return body
+}
@@ -0,0 +1,84 @@
+package from
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bridgex-eu/wirex"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name string `json:"name" form:"name"`
+}
+
+type status int
+
+func (s status) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(int(s))
+}
+
+func bind(r *http.Request, item wirex.FromRequest) wirex.Writer {
+	if err := Bind(r, item); err != nil {
+		return err
+	}
+
+	return status(http.StatusOK)
+}
+
+func TestMaxBodySize(t *testing.T) {
+	engine := wirex.New()
+
+	var u user
+	engine.Route("/json").Post(func(r *http.Request) wirex.Writer { return bind(r, Json(&u)) })
+	engine.Route("/form").Post(func(r *http.Request) wirex.Writer { return bind(r, Form(&u)) })
+	engine.Route("/large").Post(func(r *http.Request) wirex.Writer { return bind(r, Json(&u)) }).MaxBodySize(1 << 20)
+	engine.MaxBodySize(16)
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	large := `{"name": "` + strings.Repeat("a", 64) + `"}`
+
+	testPost(t, testServer.URL+"/json", wirex.MIMEApplicationJSON, `{"name": "bob"}`, http.StatusOK)
+	testPost(t, testServer.URL+"/json", wirex.MIMEApplicationJSON, large, http.StatusRequestEntityTooLarge)
+	testPost(t, testServer.URL+"/large", wirex.MIMEApplicationJSON, large, http.StatusOK)
+	testPost(t, testServer.URL+"/form", wirex.MIMEApplicationForm, "name="+strings.Repeat("a", 64), http.StatusRequestEntityTooLarge)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("name", strings.Repeat("a", 64))
+	form.Close()
+
+	testPost(t, testServer.URL+"/form", form.FormDataContentType(), body.String(), http.StatusRequestEntityTooLarge)
+}
+
+func TestMultipartForm(t *testing.T) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("name", "bob")
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set(wirex.HeaderContentType, form.FormDataContentType())
+
+	var u user
+	assert.Nil(t, Form(&u).FromRequest(req))
+	assert.Equal(t, "bob", u.Name)
+}
+
+func testPost(t *testing.T, url, contentType, body string, expectedStatus int) {
+	resp, err := http.Post(url, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Could not make POST request to %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		t.Errorf("Expected status code %d for %s, got %d", expectedStatus, url, resp.StatusCode)
+	}
+}
@@ -1,7 +1,9 @@
 package from
 
 import (
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -19,8 +21,17 @@ func Form[T any](data *T) *FormData[T] {
 
 func (f *FormData[T]) FromRequest(r *http.Request) wirex.HTTPError {
 	// Parse the form data from the request
-	err := r.ParseForm()
+	var err error
+	if isMultipart(r) {
+		err = r.ParseMultipartForm(multipartMemory)
+	} else {
+		err = r.ParseForm()
+	}
 	if err != nil {
+		if tooLarge(err) {
+			return wirex.Error(http.StatusRequestEntityTooLarge, err)
+		}
+
 		return wirex.Error(http.StatusBadRequest, err)
 	}
 
@@ -36,6 +47,20 @@ func (f *FormData[T]) FromRequest(r *http.Request) wirex.HTTPError {
 	return nil
 }
 
+// multipartMemory is the part of a multipart body kept in memory, the rest of the files is stored on disk.
+const multipartMemory = 32 << 20
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(wirex.HeaderContentType))
+	return err == nil && mediaType == wirex.MIMEMultipartForm
+}
+
+// tooLarge reports whether reading the request body failed because of its size limit.
+func tooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
 func decodeForm(form url.Values, to any) error {
 	toValue := reflect.ValueOf(to)
 
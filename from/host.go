@@ -0,0 +1,32 @@
+package from
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bridgex-eu/wirex"
+)
+
+type HostData[T decodable] struct {
+	Data *T
+	Name string
+}
+
+func (h *HostData[T]) FromRequest(r *http.Request) wirex.HTTPError {
+	val := wirex.HostValue(r, h.Name)
+	if val == "" {
+		return wirex.Error(http.StatusBadRequest, fmt.Errorf("host parameter: %s not found", h.Name))
+	}
+
+	decoded, err := decode[T](val)
+	if err != nil {
+		return wirex.Error(http.StatusBadRequest, fmt.Errorf("host parameter: %s has wrong type, value: %s", h.Name, val))
+	}
+
+	*h.Data = decoded
+	return nil
+}
+
+func Host[T decodable](name string, value *T) *HostData[T] {
+	return &HostData[T]{Data: value, Name: name}
+}
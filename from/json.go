@@ -25,6 +25,9 @@ func (j *JsonData[T]) FromRequest(r *http.Request) wirex.HTTPError {
 		if _, ok := err.(*json.UnmarshalTypeError); ok {
 			return wirex.Error(http.StatusBadRequest, err)
 		}
+		if tooLarge(err) {
+			return wirex.Error(http.StatusRequestEntityTooLarge, err)
+		}
 
 		return wirex.Error(http.StatusInternalServerError, err)
 	}
@@ -0,0 +1,8 @@
+package from
+
+import "github.com/bridgex-eu/wirex"
+
+// RouteInfo extracts the description of the route handling the request, including its metadata.
+func RouteInfo(info *wirex.RouteInfo) *ContextData[wirex.RouteInfo] {
+	return FromContext(wirex.RouteContextKey, info)
+}
@@ -4,6 +4,7 @@ go 1.22
 
 require (
 	github.com/go-playground/validator/v10 v10.16.0
+	github.com/klauspost/compress v1.18.0
 	golang.org/x/exp v0.0.0-20231219180239-dc181d75b848
 )
 
@@ -1,7 +1,9 @@
 package wirex
 
 import (
-	"net/url"
+	"path/filepath"
+	"runtime"
+	"strconv"
 )
 
 type RoutesGroup struct {
@@ -13,7 +15,17 @@ func NewRoutesGroup() *RoutesGroup {
 }
 
 func (g *RoutesGroup) Route(pattern string, handlers ...MethodHandler) *Route {
+	return g.addRoute(pattern, handlers)
+}
+
+// addRoute adds a route to the group, recording the place it was registered from. It must be
+// called directly by the exported method registering the route.
+func (g *RoutesGroup) addRoute(pattern string, handlers []MethodHandler) *Route {
 	route := Route{pattern: pattern, handlers: handlers}
+	if _, file, line, ok := runtime.Caller(2); ok {
+		route.source = filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+
 	g.routes = append(g.routes, &route)
 
 	return &route
@@ -31,13 +43,37 @@ func (g *RoutesGroup) With(key string, val any) {
 
 func (g *RoutesGroup) Group(pattern string, group *RoutesGroup, middlewares ...Middleware) {
 	for _, route := range group.routes {
-		pattern, err := url.JoinPath(pattern, route.pattern)
-		if err != nil {
-			panic(err)
+		route.pattern = joinPattern(pattern, route.pattern)
+		route.groups = append(route.groups, "group "+pattern)
+		route.middlewares = append(route.middlewares, middlewares...)
+		g.routes = append(g.routes, route)
+	}
+}
+
+// Host adds the routes of group to g, matching only requests for the given host.
+//
+// The host is either a literal name, like "api.example.com", or contains wildcard labels, like
+// "{tenant}.example.com". Values of host wildcards are available through HostValue and from.Host.
+// Routes of group that already belong to a host keep it.
+func (g *RoutesGroup) Host(host string, group *RoutesGroup, middlewares ...Middleware) {
+	for _, route := range group.routes {
+		if route.host == "" {
+			route.host = host
 		}
 
-		route.pattern = pattern
+		route.groups = append(route.groups, "host "+host)
+
 		route.middlewares = append(route.middlewares, middlewares...)
 		g.routes = append(g.routes, route)
 	}
 }
+
+// MaxBodySize limits the request body size of the routes in the group that don't have a limit of their own.
+// See Route.MaxBodySize.
+func (g *RoutesGroup) MaxBodySize(n int64) {
+	for _, route := range g.routes {
+		if route.maxBodySize == 0 {
+			route.MaxBodySize(n)
+		}
+	}
+}
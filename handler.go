@@ -36,16 +36,49 @@ type HandlerFunc func(*http.Request) Writer
 type MethodHandler struct {
 	method  string
 	handler http.Handler
+	any     bool // Registered by Route.Any, so a handler for the specific method may replace it.
 }
 
+// Handler adapts a HandlerFunc to http.Handler.
+//
+// A nil Writer returned by the HandlerFunc is answered with 204 (No Content). For HEAD requests, the
+// response body written by the Writer is discarded, so GET handlers serve HEAD requests unchanged.
 func Handler(h HandlerFunc) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w = &headResponseWriter{w}
+		}
+
 		wr := h(r)
+		if wr == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
 
 		if err, ok := wr.(error); ok {
-			slog.Error("handler error", "error", err)
+			if engine := engineFrom(r); engine != nil && engine.ErrorHandler != nil {
+				if handled := engine.ErrorHandler(r, err); handled != nil {
+					wr = handled
+				}
+			} else {
+				slog.Error("handler error", "error", err)
+			}
 		}
 
 		wr.WriteResponse(w, r)
 	})
 }
+
+// headResponseWriter discards the response body of HEAD requests, keeping the headers and status.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for http.ResponseController.
+func (w *headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,36 @@
+package wirex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerNoContent(t *testing.T) {
+	engine := New()
+	engine.Route("/users/{id}").Delete(func(r *http.Request) Writer { return nil })
+
+	recorder := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestHandlerHead(t *testing.T) {
+	engine := New()
+	engine.Route("/users").Get(func(r *http.Request) Writer { return text("users") })
+
+	handler := engine.Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "/users", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, "users", recorder.Body.String())
+}
@@ -0,0 +1,73 @@
+package wirex
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const HostContextKey = "wirex-host"
+
+// hostPattern matches request hosts against a pattern with wildcard labels, like "{tenant}.example.com".
+// Hosts without wildcards are left to http.ServeMux, which supports them natively.
+type hostPattern struct {
+	labels []string
+}
+
+// parseHostPattern returns the pattern for host, or nil if host has no wildcard labels.
+func parseHostPattern(host string) *hostPattern {
+	if !strings.Contains(host, "{") {
+		return nil
+	}
+
+	return &hostPattern{labels: strings.Split(strings.ToLower(host), ".")}
+}
+
+func (h *hostPattern) match(host string) (map[string]string, bool) {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	labels := strings.Split(strings.ToLower(host), ".")
+	if len(labels) != len(h.labels) {
+		return nil, false
+	}
+
+	values := map[string]string{}
+	for i, label := range h.labels {
+		if name, ok := wildcardName(label); ok {
+			if labels[i] == "" {
+				return nil, false
+			}
+
+			values[name] = labels[i]
+			continue
+		}
+
+		if label != labels[i] {
+			return nil, false
+		}
+	}
+
+	return values, true
+}
+
+func wildcardName(label string) (string, bool) {
+	if len(label) < 3 || label[0] != '{' || label[len(label)-1] != '}' {
+		return "", false
+	}
+
+	return label[1 : len(label)-1], true
+}
+
+// HostValue returns the value of the named wildcard of the host pattern matched by the request.
+// It returns the empty string if the route has no such wildcard.
+func HostValue(r *http.Request, name string) string {
+	values, _ := r.Context().Value(HostContextKey).(map[string]string)
+	return values[name]
+}
+
+func withHostValues(r *http.Request, values map[string]string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), HostContextKey, values))
+}
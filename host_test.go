@@ -0,0 +1,75 @@
+package wirex
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type text string
+
+func (t text) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, string(t))
+}
+
+func TestHostRoutes(t *testing.T) {
+	engine := New()
+
+	api := NewRoutesGroup()
+	api.Route("/users").Get(func(r *http.Request) Writer { return text("api") })
+
+	tenants := NewRoutesGroup()
+	tenants.Route("/users").Get(func(r *http.Request) Writer { return text("tenant " + HostValue(r, "tenant")) })
+
+	engine.Host("api.example.com", api)
+	engine.Host("{tenant}.example.com", tenants)
+	engine.Route("/users").Get(func(r *http.Request) Writer { return text("default") })
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	testHost(t, testServer, "api.example.com", "api")
+	testHost(t, testServer, "acme.example.com", "tenant acme")
+	testHost(t, testServer, "Acme.Example.com:8080", "tenant acme")
+	testHost(t, testServer, "a.b.example.com", "default")
+	testHost(t, testServer, "example.org", "default")
+}
+
+func TestHostNotFound(t *testing.T) {
+	engine := New()
+
+	tenants := NewRoutesGroup()
+	tenants.Route("/users").Get(okHandler)
+	engine.Host("{tenant}.example.com", tenants)
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, testServer.URL+"/users", nil)
+	req.Host = "example.org"
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Could not make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func testHost(t *testing.T, server *httptest.Server, host string, expectedBody string) {
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/users", nil)
+	req.Host = host
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Could not make GET request for host %s: %v", host, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, expectedBody, string(body), "host %s", host)
+}
@@ -0,0 +1,69 @@
+package wirex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which response buffers are left to the garbage collector instead
+// of being pooled, so one large response doesn't pin its memory for good.
+const maxPooledBuffer = 64 << 10
+
+var responseBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return responseBuffers.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		buf.Reset()
+		responseBuffers.Put(buf)
+	}
+}
+
+// WriteJSON encodes v as JSON and writes it with the given status, setting Content-Length and the
+// content type, unless a Content-Type header is already set.
+//
+// The value is encoded into a buffer before anything is written, so an encoding failure, like a channel
+// or a cyclic value, is answered with a proper 500 (Internal Server Error) and returned. When the Engine
+// is in debug mode with PrettyJSON set, the JSON is indented.
+func WriteJSON(w http.ResponseWriter, r *http.Request, status int, contentType string, v any) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	encoder := json.NewEncoder(buf)
+	if engine := engineFrom(r); engine != nil && engine.Debug && engine.PrettyJSON {
+		encoder.SetIndent("", "  ")
+	}
+
+	if err := encoder.Encode(v); err != nil {
+		err = fmt.Errorf("encode json response: %w", err)
+
+		// The fallback error is always encodable, so this doesn't recurse further.
+		w.Header().Del(HeaderContentType)
+		Error(http.StatusInternalServerError, err).WriteResponse(w, r)
+		return err
+	}
+
+	header := w.Header()
+	if header.Get(HeaderContentType) == "" {
+		header.Set(HeaderContentType, contentType)
+	}
+	header.Set(HeaderContentLength, strconv.Itoa(buf.Len()))
+
+	w.WriteHeader(status)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.Error("cannot write json to response", "error", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package wirex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJSON(t *testing.T) {
+	engine := New()
+	engine.Route("/user").Get(func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			WriteJSON(w, r, http.StatusOK, MIMEApplicationJSON, map[string]string{"name": "Ann"})
+		})
+	})
+	engine.Route("/broken").Get(func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			WriteJSON(w, r, http.StatusOK, MIMEApplicationJSON, map[string]any{"updates": make(chan int)})
+		})
+	})
+
+	handler := engine.Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/user", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "{\"name\":\"Ann\"}\n", recorder.Body.String())
+	assert.Equal(t, strconv.Itoa(recorder.Body.Len()), recorder.Header().Get(HeaderContentLength))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, MIMEApplicationJSON, recorder.Header().Get(HeaderContentType))
+	assert.JSONEq(t, `{"message": "Internal Server Error"}`, recorder.Body.String())
+
+	engine.Debug, engine.PrettyJSON = true, true
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/user", nil))
+	assert.Equal(t, "{\n  \"name\": \"Ann\"\n}\n", recorder.Body.String())
+}
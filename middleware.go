@@ -1,9 +1,20 @@
 package wirex
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -28,6 +39,16 @@ func with(key string, val any) Middleware {
 	}
 }
 
+func maxBodySize(n int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func Logger() Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -37,25 +58,238 @@ func Logger() Middleware {
 			next.ServeHTTP(wrappedWriter, r)
 
 			duration := time.Since(start)
-			slog.Info("request", "method", r.Method, "path", r.URL.EscapedPath(), "status", wrappedWriter.StatusCode, "duration", duration)
+
+			var firstByte time.Duration
+			if wrappedWriter.HeaderWritten {
+				firstByte = wrappedWriter.FirstByte.Sub(start)
+			}
+
+			slog.Info("request", "method", r.Method, "path", r.URL.EscapedPath(), "status", wrappedWriter.StatusCode,
+				"bytes", wrappedWriter.BytesWritten, "duration", duration, "first_byte", firstByte)
+		})
+	}
+}
+
+// Recover returns a middleware that recovers from panics in the handlers, extractors and writers of the
+// routes it wraps.
+//
+// A recovered panic is logged with its stack trace and answered with 500 (Internal Server Error), unless the
+// response was already started, in which case the client gets it truncated. When the Engine is in debug mode,
+// the response includes the panic value and the stack trace as its causes. A panic with http.ErrAbortHandler
+// is passed on, as it's meant to abort the response.
+//
+// Usage Example:
+//
+//	engine.Use(wirex.Recover())
+func Recover() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := NewResponseWriter(w)
+
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+
+				if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(p)
+				}
+
+				stack := debug.Stack()
+				if recovered, ok := p.(*recoveredPanic); ok {
+					p, stack = recovered.value, recovered.stack
+				}
+
+				slog.Error("handler panic", "panic", p, "method", r.Method, "path", r.URL.EscapedPath(), "stack", string(stack))
+
+				// The response is already on its way, the client gets it truncated.
+				if rw.HeaderWritten {
+					return
+				}
+
+				// The panic and the stack are part of the error, so they're only sent to the client in debug mode.
+				err := fmt.Errorf("panic: %v\n\n%s", p, stack)
+				NewError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), err).WriteResponse(rw, r)
+			}()
+
+			next.ServeHTTP(rw, r)
 		})
 	}
 }
 
-// ResponseWriter is a custom http.ResponseWriter that captures the status code
+// maxOverrideForm is the largest urlencoded body MethodOverride looks into for the "_method" field.
+const maxOverrideForm = 64 << 10
+
+// MethodOverride returns a middleware that lets POST requests stand in for the methods HTML forms and
+// some proxies can't send. The method is taken from the X-HTTP-Method-Override header or, for urlencoded
+// form submissions up to 64 KB, from the "_method" form field, and is applied only if it's one of the
+// allowed methods. PUT, PATCH and DELETE are allowed when none are given.
+//
+// The form is read without being consumed, so the route's MaxBodySize still applies to the body, and
+// multipart forms are left alone, as they can't be parsed without reading them whole.
+//
+// The request method has to be rewritten before the request is routed, so the middleware is meant
+// to be added with Engine.Pre.
+//
+// Usage Example:
+//
+//	engine.Pre(wirex.MethodOverride())
+func MethodOverride(allowed ...string) Middleware {
+	if len(allowed) == 0 {
+		allowed = []string{http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			method := r.Header.Get(HeaderXHTTPMethodOverride)
+			if method == "" && isURLEncodedForm(r) {
+				r = r.WithContext(r.Context())
+				method = peekFormValue(r, "_method")
+			}
+
+			method = strings.ToUpper(strings.TrimSpace(method))
+			if slices.Contains(allowed, method) {
+				r = r.WithContext(r.Context())
+				r.Method = method
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isURLEncodedForm(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+	if err != nil {
+		return false
+	}
+
+	return mediaType == MIMEApplicationForm
+}
+
+// peekFormValue returns the value of a field of the urlencoded body, which is put back for the handler
+// to read. Bodies larger than maxOverrideForm are not parsed.
+func peekFormValue(r *http.Request, key string) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+
+	head, err := io.ReadAll(io.LimitReader(r.Body, maxOverrideForm+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+
+	if err != nil || len(head) > maxOverrideForm {
+		return ""
+	}
+
+	values, err := url.ParseQuery(string(head))
+	if err != nil {
+		return ""
+	}
+
+	return values.Get(key)
+}
+
+// ResponseWriter is a custom http.ResponseWriter that records the status code, the size and the timing
+// of the response, for middlewares that measure or inspect responses.
+//
+// It forwards http.Flusher, http.Hijacker and io.ReaderFrom to the original ResponseWriter, and Unwrap
+// returns it, so streaming, WebSockets and http.ResponseController keep working behind middlewares.
+// Only the first WriteHeader call is forwarded, like net/http does.
 type ResponseWriter struct {
 	http.ResponseWriter
-	StatusCode int
+	StatusCode    int       // The status code sent, 200 until WriteHeader is called.
+	BytesWritten  int64     // The number of body bytes written.
+	HeaderWritten bool      // Whether the status and headers were sent.
+	FirstByte     time.Time // When the status and headers were sent.
 }
 
-// NewResponseWriter creates a new ResponseWriter
+var (
+	_ http.Flusher  = &ResponseWriter{}
+	_ http.Hijacker = &ResponseWriter{}
+	_ io.ReaderFrom = &ResponseWriter{}
+)
+
+// NewResponseWriter creates a new ResponseWriter, or returns w if it's already one, so nested
+// middlewares share the same records.
 func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+
 	// Default the status code to 200, as that is what net/http defaults to
-	return &ResponseWriter{w, http.StatusOK}
+	return &ResponseWriter{ResponseWriter: w, StatusCode: http.StatusOK}
 }
 
-// WriteHeader captures the status code and calls the original WriteHeader
+// WriteHeader captures the status code and calls the original WriteHeader. Informational statuses
+// are passed on, while calls after the status was sent are ignored.
 func (rw *ResponseWriter) WriteHeader(code int) {
+	if rw.HeaderWritten {
+		return
+	}
+
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		rw.ResponseWriter.WriteHeader(code)
+		return
+	}
+
 	rw.StatusCode = code
+	rw.markWritten()
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *ResponseWriter) Write(b []byte) (int, error) {
+	rw.markWritten()
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.BytesWritten += int64(n)
+
+	return n, err
+}
+
+// ReadFrom copies src to the response, using the io.ReaderFrom of the original ResponseWriter if it
+// has one, like the sendfile support of net/http.
+func (rw *ResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	rw.markWritten()
+
+	n, err := io.Copy(rw.ResponseWriter, src)
+	rw.BytesWritten += n
+
+	return n, err
+}
+
+// Flush sends any buffered data to the client, if the original ResponseWriter supports it.
+func (rw *ResponseWriter) Flush() {
+	rw.markWritten()
+	http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Hijack lets the caller take over the connection, if the original ResponseWriter supports it.
+func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(rw.ResponseWriter).Hijack()
+	if err == nil {
+		rw.HeaderWritten = true
+	}
+
+	return conn, buf, err
+}
+
+// Unwrap returns the original ResponseWriter, for http.ResponseController.
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *ResponseWriter) markWritten() {
+	if !rw.HeaderWritten {
+		rw.HeaderWritten = true
+		rw.FirstByte = time.Now()
+	}
+}
@@ -0,0 +1,102 @@
+package wirex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	engine := New()
+
+	engine.Route("/panic").Get(func(r *http.Request) Writer { panic("boom") })
+	engine.Route("/abort").Get(func(r *http.Request) Writer { panic(http.ErrAbortHandler) })
+	engine.Use(Recover())
+
+	handler := engine.Handler()
+
+	message, causes := recoverMessage(t, handler, "/panic")
+	assert.Equal(t, http.StatusText(http.StatusInternalServerError), message)
+	assert.Empty(t, causes)
+
+	engine.Debug = true
+
+	message, causes = recoverMessage(t, handler, "/panic")
+	assert.Equal(t, http.StatusText(http.StatusInternalServerError), message)
+	assert.Len(t, causes, 1)
+	assert.Contains(t, causes[0], "panic: boom")
+	assert.Contains(t, causes[0], "middleware_test.go")
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+}
+
+func recoverMessage(t *testing.T, handler http.Handler, path string) (string, []string) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	var body struct {
+		Message string   `json:"message"`
+		Causes  []string `json:"causes"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("Could not decode the response for %s: %v", path, err)
+	}
+
+	return body.Message, body.Causes
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	engine := New()
+	engine.Route("/stream").Get(func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("data: ready\n\n"))
+			http.NewResponseController(w).Flush()
+		})
+	})
+	engine.Use(Logger())
+
+	recorder := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+	assert.True(t, recorder.Flushed)
+}
+
+func TestResponseWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	rw := NewResponseWriter(recorder)
+	assert.Same(t, rw, NewResponseWriter(rw))
+
+	rw.WriteHeader(http.StatusCreated)
+	rw.WriteHeader(http.StatusInternalServerError)
+	rw.Write([]byte("hello "))
+	rw.ReadFrom(strings.NewReader("world"))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, http.StatusCreated, rw.StatusCode)
+	assert.Equal(t, int64(len("hello world")), rw.BytesWritten)
+	assert.True(t, rw.HeaderWritten)
+	assert.False(t, rw.FirstByte.IsZero())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := http.NewResponseController(NewResponseWriter(w)).Hijack()
+		if assert.NoError(t, err) {
+			conn.Write([]byte("HTTP/1.1 204 No Content\r\n\r\n"))
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+}
@@ -0,0 +1,184 @@
+package wirex
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TrailingSlash defines how the Engine treats requests that differ from a route only by a trailing slash.
+type TrailingSlash int
+
+const (
+	// TrailingSlashDefault keeps the http.ServeMux rules: a pattern ending in a slash matches its whole
+	// subtree, and a request for "/users" is redirected to a "/users/" route.
+	TrailingSlashDefault TrailingSlash = iota
+	// TrailingSlashStrict treats "/users" and "/users/" as distinct paths, each matching only itself.
+	TrailingSlashStrict
+	// TrailingSlashRedirect redirects the other form to the registered one, with 301 (Moved Permanently)
+	// for GET and HEAD requests and 308 (Permanent Redirect) for other methods.
+	TrailingSlashRedirect
+	// TrailingSlashMatchBoth serves both forms with the registered route.
+	TrailingSlashMatchBoth
+)
+
+type originalURLKey struct{}
+
+// joinPattern joins a group prefix and a route pattern. Unlike url.JoinPath, it leaves wildcards
+// unescaped and keeps the trailing slash of the route pattern as it is.
+func joinPattern(prefix, pattern string) string {
+	if pattern == "" {
+		return prefix
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(pattern, "/")
+}
+
+// normalize applies the path policies of the Engine to a request before it's matched.
+func (e *Engine) normalize(r *http.Request) *http.Request {
+	if e.MergeSlashes && strings.Contains(r.URL.Path, "//") {
+		u := *r.URL
+		u.Path, u.RawPath = mergeSlashes(u.Path), mergeSlashes(u.RawPath)
+
+		r = r.WithContext(r.Context())
+		r.URL = &u
+	}
+
+	if e.CaseInsensitive {
+		lower := strings.ToLower(r.URL.Path)
+		if lower != r.URL.Path {
+			// Matching is done on the lower-cased path, restoreURL hands the original to the route.
+			u := *r.URL
+			u.Path, u.RawPath = lower, strings.ToLower(u.RawPath)
+
+			r = r.WithContext(context.WithValue(r.Context(), originalURLKey{}, r.URL))
+			r.URL = &u
+		}
+	}
+
+	return r
+}
+
+func mergeSlashes(path string) string {
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+
+	return path
+}
+
+// restoreURL undoes the lower-casing of a case-insensitive match, so the route sees the original
+// request URL and path values.
+func (e *Engine) restoreURL(params []pathParam, next http.Handler) http.Handler {
+	if !e.CaseInsensitive {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		original, ok := r.Context().Value(originalURLKey{}).(*url.URL)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		r.URL = original
+
+		segments := strings.Split(original.EscapedPath(), "/")[1:]
+		for _, param := range params {
+			if param.segment >= len(segments) {
+				continue
+			}
+
+			value := segments[param.segment]
+			if param.multi {
+				value = strings.Join(segments[param.segment:], "/")
+			}
+
+			if unescaped, err := url.PathUnescape(value); err == nil {
+				r.SetPathValue(param.name, unescaped)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// lowerLiterals lower-cases a http.ServeMux pattern path, leaving wildcard names untouched.
+func lowerLiterals(path string) string {
+	var b strings.Builder
+
+	for path != "" {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			b.WriteString(strings.ToLower(path))
+			break
+		}
+
+		end := start + strings.IndexByte(path[start:], '}') + 1
+		b.WriteString(strings.ToLower(path[:start]))
+		b.WriteString(path[start:end])
+		path = path[end:]
+	}
+
+	return b.String()
+}
+
+// exactSlash makes a pattern ending in a slash match only that path instead of its whole subtree.
+func exactSlash(pattern routePattern) routePattern {
+	if strings.HasSuffix(pattern.path, "/") {
+		pattern.path += "{$}"
+		pattern.shape += "{$}"
+	}
+
+	return pattern
+}
+
+// toggleSlash returns the form of a http.ServeMux pattern without or with the trailing slash. Patterns
+// ending in a multi wildcard and the root pattern have no other form.
+func toggleSlash(pattern string) (string, bool) {
+	if other, ok := strings.CutSuffix(pattern, "/{$}"); ok {
+		// The root pattern, possibly prefixed by a method and host.
+		if !strings.Contains(other, "/") {
+			return "", false
+		}
+
+		return other, true
+	}
+
+	if strings.HasSuffix(pattern, "...}") {
+		return "", false
+	}
+
+	return pattern + "/{$}", true
+}
+
+// trailingSlashHandler returns the handler for the other form of a route, according to the policy.
+func (e *Engine) trailingSlashHandler(route http.Handler) http.Handler {
+	switch e.TrailingSlash {
+	case TrailingSlashRedirect:
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			target := r.URL.EscapedPath()
+			if trimmed, ok := strings.CutSuffix(target, "/"); ok {
+				target = trimmed
+			} else {
+				target += "/"
+			}
+
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+
+			status := http.StatusPermanentRedirect
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+
+			http.Redirect(w, r, target, status)
+		})
+	case TrailingSlashMatchBoth:
+		return route
+	default:
+		return http.HandlerFunc(e.notFound)
+	}
+}
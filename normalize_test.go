@@ -0,0 +1,88 @@
+package wirex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinPattern(t *testing.T) {
+	assert.Equal(t, "/test/", joinPattern("/", "/test/"))
+	assert.Equal(t, "/api/users/{id}", joinPattern("/api", "/users/{id}"))
+	assert.Equal(t, "/api/users", joinPattern("/api/", "users"))
+	assert.Equal(t, "/api/", joinPattern("/api", "/"))
+	assert.Equal(t, "/api", joinPattern("/api", ""))
+}
+
+func newSlashServer(t *testing.T, policy TrailingSlash) *httptest.Server {
+	engine := New()
+	engine.TrailingSlash = policy
+
+	users := NewRoutesGroup()
+	users.Route("/").Get(func(r *http.Request) Writer { return text("list") }).Post(okHandler)
+	users.Route("/{id}").Get(func(r *http.Request) Writer { return text("user " + r.PathValue("id")) })
+	engine.Group("/users", users)
+
+	server := httptest.NewServer(engine.Handler())
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestTrailingSlashStrict(t *testing.T) {
+	server := newSlashServer(t, TrailingSlashStrict)
+
+	testBody(t, server, "/users/", http.StatusOK, "list")
+	testBody(t, server, "/users", http.StatusNotFound, "")
+	testBody(t, server, "/users/42", http.StatusOK, "user 42")
+	testBody(t, server, "/users/42/", http.StatusNotFound, "")
+	testBody(t, server, "/users/42/posts", http.StatusNotFound, "")
+}
+
+func TestTrailingSlashRedirect(t *testing.T) {
+	server := newSlashServer(t, TrailingSlashRedirect)
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	resp, err := client.Get(server.URL + "/users?page=2")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "/users/?page=2", resp.Header.Get(HeaderLocation))
+
+	resp, err = client.Post(server.URL+"/users", MIMEApplicationJSON, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPermanentRedirect, resp.StatusCode)
+
+	resp, err = client.Get(server.URL + "/users/42/")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "/users/42", resp.Header.Get(HeaderLocation))
+}
+
+func TestTrailingSlashMatchBoth(t *testing.T) {
+	server := newSlashServer(t, TrailingSlashMatchBoth)
+
+	testBody(t, server, "/users", http.StatusOK, "list")
+	testBody(t, server, "/users/", http.StatusOK, "list")
+	testBody(t, server, "/users/42/", http.StatusOK, "user 42")
+}
+
+func TestCaseInsensitiveAndMergeSlashes(t *testing.T) {
+	engine := New()
+	engine.CaseInsensitive = true
+	engine.MergeSlashes = true
+
+	engine.Route("/Users/{name}/{rest...}").Get(func(r *http.Request) Writer {
+		return text(r.URL.Path + " " + r.PathValue("name") + " " + r.PathValue("rest"))
+	})
+	engine.Route("/files/{name:[A-Z]+}").Get(func(r *http.Request) Writer { return text("file " + r.PathValue("name")) })
+
+	server := httptest.NewServer(engine.Handler())
+	defer server.Close()
+
+	testBody(t, server, "/users/Bob/A/b", http.StatusOK, "/users/Bob/A/b Bob A/b")
+	testBody(t, server, "/USERS//Bob/x", http.StatusOK, "/USERS/Bob/x Bob x")
+	testBody(t, server, "/FILES/REPORT", http.StatusOK, "file REPORT")
+	testBody(t, server, "/files/report", http.StatusNotFound, "")
+}
@@ -0,0 +1,134 @@
+package wirex
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Constraints holds the named wildcard constraints usable in route patterns, like "/users/{id:int}".
+// A constraint that isn't listed here is compiled as a regular expression matching the whole segment.
+var Constraints = map[string]func(string) bool{
+	"int": func(s string) bool {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	},
+	"uint": func(s string) bool {
+		_, err := strconv.ParseUint(s, 10, 64)
+		return err == nil
+	},
+	"float": func(s string) bool {
+		_, err := strconv.ParseFloat(s, 64)
+		return err == nil
+	},
+	"bool": func(s string) bool {
+		_, err := strconv.ParseBool(s)
+		return err == nil
+	},
+	"uuid": func(s string) bool {
+		_, err := uuid.Parse(s)
+		return err == nil
+	},
+	"alpha": func(s string) bool {
+		return s != "" && strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }) < 0
+	},
+	"alnum": func(s string) bool {
+		return s != "" && strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) < 0
+	},
+}
+
+// pathParam is a wildcard of a route pattern.
+type pathParam struct {
+	name       string
+	segment    int  // The index of the path segment holding the wildcard.
+	multi      bool // Whether the wildcard matches the remaining segments.
+	constraint string
+	check      func(string) bool // Nil for wildcards without a constraint.
+}
+
+// routePattern is a route pattern translated for http.ServeMux.
+type routePattern struct {
+	path   string      // The pattern without constraints, as understood by http.ServeMux.
+	shape  string      // The path without wildcard names. Patterns with the same shape match the same requests.
+	params []pathParam // The wildcards of the pattern, in order.
+}
+
+// parsePattern translates a route pattern with typed wildcards, like "/users/{id:int}" or
+// "/files/{name:[a-z0-9-]+}", into a plain http.ServeMux pattern and the list of its wildcards.
+func parsePattern(pattern string) (routePattern, error) {
+	var path, shape strings.Builder
+	var params []pathParam
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '{' {
+			path.WriteByte(pattern[i])
+			shape.WriteByte(pattern[i])
+			continue
+		}
+
+		// Constraints are regular expressions, which may contain braces themselves.
+		end, depth := i+1, 1
+		for ; end < len(pattern) && depth > 0; end++ {
+			switch pattern[end] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+		}
+		if depth > 0 {
+			return routePattern{}, fmt.Errorf("pattern %q: unclosed wildcard", pattern)
+		}
+
+		name, constraint, _ := strings.Cut(pattern[i+1:end-1], ":")
+		i = end - 1
+
+		path.WriteString("{" + name + "}")
+		if name == "$" {
+			shape.WriteString("{$}")
+			continue
+		}
+
+		multi := strings.HasSuffix(name, "...")
+		if multi {
+			shape.WriteString("{...}")
+		} else {
+			shape.WriteString("{}")
+		}
+
+		param := pathParam{
+			name:       strings.TrimSuffix(name, "..."),
+			segment:    strings.Count(shape.String(), "/") - 1,
+			multi:      multi,
+			constraint: constraint,
+		}
+		if constraint != "" {
+			check, err := compileConstraint(constraint)
+			if err != nil {
+				return routePattern{}, fmt.Errorf("pattern %q: wildcard %s: %w", pattern, param.name, err)
+			}
+			param.check = check
+		}
+
+		params = append(params, param)
+	}
+
+	return routePattern{path: path.String(), shape: shape.String(), params: params}, nil
+}
+
+func compileConstraint(constraint string) (func(string) bool, error) {
+	if check, ok := Constraints[constraint]; ok {
+		return check, nil
+	}
+
+	re, err := regexp.Compile("^(?:" + constraint + ")$")
+	if err != nil {
+		return nil, err
+	}
+
+	return re.MatchString, nil
+}
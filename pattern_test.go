@@ -0,0 +1,98 @@
+package wirex
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePattern(t *testing.T) {
+	pattern, err := parsePattern("/files/{year:[0-9]{4}}/{name}/{rest...}")
+	assert.NoError(t, err)
+	assert.Equal(t, "/files/{year}/{name}/{rest...}", pattern.path)
+	assert.Equal(t, "/files/{}/{}/{...}", pattern.shape)
+	assert.Len(t, pattern.params, 3)
+	assert.Equal(t, "[0-9]{4}", pattern.params[0].constraint)
+	assert.Equal(t, "rest", pattern.params[2].name)
+
+	_, err = parsePattern("/files/{name:[a-z}")
+	assert.Error(t, err)
+
+	_, err = parsePattern("/files/{name:(}")
+	assert.Error(t, err)
+}
+
+func TestConstrainedRoutes(t *testing.T) {
+	engine := New()
+
+	engine.Route("/users/{id:int}").Get(func(r *http.Request) Writer { return text("id " + r.PathValue("id")) })
+	engine.Route("/users/{name:alpha}").Get(func(r *http.Request) Writer { return text("name " + r.PathValue("name")) })
+	engine.Route("/files/{name:[a-z0-9-]+}").Get(func(r *http.Request) Writer { return text("file " + r.PathValue("name")) })
+	engine.Route("/files/{other}").Post(func(r *http.Request) Writer { return text("post " + r.PathValue("other")) })
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	testBody(t, testServer, "/users/42", http.StatusOK, "id 42")
+	testBody(t, testServer, "/users/bob", http.StatusOK, "name bob")
+	testBody(t, testServer, "/users/bob42", http.StatusNotFound, "")
+	testBody(t, testServer, "/files/report-2024", http.StatusOK, "file report-2024")
+	testBody(t, testServer, "/files/Report", http.StatusNotFound, "")
+
+	assert.Equal(t, []RouteInfo{
+		{Method: http.MethodGet, Pattern: "/users/{id:int}", Constraints: map[string]string{"id": "int"}},
+		{Method: http.MethodGet, Pattern: "/users/{name:alpha}", Constraints: map[string]string{"name": "alpha"}},
+		{Method: http.MethodGet, Pattern: "/files/{name:[a-z0-9-]+}", Constraints: map[string]string{"name": "[a-z0-9-]+"}},
+		{Method: http.MethodPost, Pattern: "/files/{other}"},
+	}, engine.Routes())
+}
+
+func TestConstrainedFallback(t *testing.T) {
+	engine := New()
+
+	engine.Route("/users/{id:int}").Get(func(r *http.Request) Writer { return text("id " + r.PathValue("id")) })
+	engine.Route("/users/{slug}").Get(func(r *http.Request) Writer { return text("slug " + r.PathValue("slug")) })
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	testBody(t, testServer, "/users/42", http.StatusOK, "id 42")
+	testBody(t, testServer, "/users/bob", http.StatusOK, "slug bob")
+}
+
+func TestConstrainedFallThrough(t *testing.T) {
+	engine := New()
+	engine.CaseInsensitive = true
+
+	engine.Route("/users/{id:int}").Get(func(r *http.Request) Writer { return text("id " + r.PathValue("id")) })
+	engine.Route("/users/{name:alpha}/{page:int}").Get(func(r *http.Request) Writer { return text("page " + r.PathValue("page")) })
+	engine.Route("/users/{rest...}").Get(func(r *http.Request) Writer { return text("rest " + r.PathValue("rest")) })
+	engine.Route("/files/{name:alpha}").Get(func(r *http.Request) Writer { return text("file " + r.PathValue("name")) })
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	testBody(t, testServer, "/users/42", http.StatusOK, "id 42")
+	testBody(t, testServer, "/users/Bob", http.StatusOK, "rest Bob")
+	testBody(t, testServer, "/users/bob/2", http.StatusOK, "page 2")
+	testBody(t, testServer, "/users/bob/posts", http.StatusOK, "rest bob/posts")
+	testBody(t, testServer, "/files/42", http.StatusNotFound, "")
+}
+
+func testBody(t *testing.T, server *httptest.Server, path string, expectedStatus int, expectedBody string) {
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("Could not make GET request to %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, expectedStatus, resp.StatusCode, "path %s", path)
+
+	if expectedStatus == http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, expectedBody, string(body), "path %s", path)
+	}
+}
@@ -0,0 +1,165 @@
+package wirex
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Problem is an HTTPError written as an RFC 9457 problem details object, with the
+// "application/problem+json" content type.
+//
+// Extensions are written as additional members of the object, next to the standard ones. When the Engine
+// is in debug mode, the messages of the Err chain are added as the "causes" member.
+//
+// See RFC 9457: https://datatracker.ietf.org/doc/html/rfc9457
+type Problem struct {
+	Type       string         // A URI reference identifying the problem type. "about:blank" when empty.
+	Title      string         // A short summary of the problem type. The status text when empty.
+	Status     int            // The HTTP status code.
+	Detail     string         // An explanation specific to this occurrence of the problem.
+	Instance   string         // A URI reference identifying this occurrence of the problem.
+	Extensions map[string]any // Additional members of the problem object.
+	Err        error          // The underlying error, only sent to the client in debug mode.
+}
+
+var _ HTTPError = &Problem{}
+
+// NewProblem returns a Problem with the given status and detail, wrapping err, which may be nil.
+//
+// Usage Example:
+//
+//	return wirex.NewProblem(http.StatusForbidden, "Your account doesn't own this project.", err).
+//		With("project", id)
+func NewProblem(status int, detail string, err error) *Problem {
+	return &Problem{Status: status, Detail: detail, Err: err}
+}
+
+// With sets an extension member of the Problem.
+func (p *Problem) With(key string, val any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = map[string]any{}
+	}
+
+	p.Extensions[key] = val
+	return p
+}
+
+// MarshalJSON writes the standard members of the problem together with its extensions.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for key, val := range p.Extensions {
+		members[key] = val
+	}
+
+	members["type"] = p.Type
+	if p.Type == "" {
+		members["type"] = "about:blank"
+	}
+
+	members["title"] = p.Title
+	if p.Title == "" {
+		members["title"] = http.StatusText(p.Status)
+	}
+
+	members["status"] = p.Status
+
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+func (p *Problem) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(HeaderContentType, MIMEApplicationProblemJSON)
+
+	problem := p
+	if engine := engineFrom(r); engine != nil && engine.Debug && p.Err != nil {
+		var causes []string
+		for err := p.Err; err != nil; err = errors.Unwrap(err) {
+			causes = append(causes, err.Error())
+		}
+
+		debugProblem := *p
+		debugProblem.Extensions = map[string]any{"causes": causes}
+		for key, val := range p.Extensions {
+			debugProblem.Extensions[key] = val
+		}
+		problem = &debugProblem
+	}
+
+	if err := WriteJSON(w, r, p.Status, MIMEApplicationProblemJSON, problem); err != nil {
+		slog.Error("cannot write json to response", "error", err)
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Err != nil {
+		return p.Err.Error()
+	}
+	if p.Detail != "" {
+		return p.Detail
+	}
+
+	return http.StatusText(p.Status)
+}
+
+// Unwrap returns the underlying error.
+func (p *Problem) Unwrap() error {
+	return p.Err
+}
+
+// problemFrom converts a DefaultHTTPError into a Problem, keeping its code and details as extensions.
+func problemFrom(e *DefaultHTTPError) *Problem {
+	problem := &Problem{Status: e.Status, Err: e.Err}
+	if e.Message != http.StatusText(e.Status) {
+		problem.Detail = e.Message
+	}
+
+	if e.Code != "" {
+		problem.With("code", e.Code)
+	}
+	if e.Details != nil {
+		problem.With("details", e.Details)
+	}
+
+	return problem
+}
+
+// unmatched answers a request no route matches, with 405 (Method Not Allowed) and the Allow header
+// if a route matches its path with another method, or with 404 (Not Found) otherwise.
+func (e *Engine) unmatched(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, method := range e.methods {
+		probe := r.WithContext(r.Context())
+		probe.Method = method
+
+		if _, pattern := e.mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+
+	if len(allowed) == 0 {
+		e.notFound(w, r)
+		return
+	}
+
+	w.Header().Set(HeaderAllow, strings.Join(allowed, ", "))
+	NewProblem(http.StatusMethodNotAllowed, "", nil).WriteResponse(w, r)
+}
+
+// notFound answers a request no route matches, honoring ProblemDetails.
+func (e *Engine) notFound(w http.ResponseWriter, r *http.Request) {
+	if !e.ProblemDetails {
+		http.NotFound(w, r)
+		return
+	}
+
+	NewProblem(http.StatusNotFound, "", nil).WriteResponse(w, r)
+}
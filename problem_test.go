@@ -0,0 +1,75 @@
+package wirex
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemResponse(t *testing.T) {
+	problem := NewProblem(http.StatusForbidden, "Your account doesn't own this project.", errors.New("owner mismatch")).
+		With("project", "42")
+	problem.Type = "https://example.com/probs/not-owner"
+
+	recorder := problemRequest(New(), http.MethodGet, "/items", problem)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, MIMEApplicationProblemJSON, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, map[string]any{
+		"type":    "https://example.com/probs/not-owner",
+		"title":   "Forbidden",
+		"status":  float64(http.StatusForbidden),
+		"detail":  "Your account doesn't own this project.",
+		"project": "42",
+	}, decodeProblem(t, recorder))
+}
+
+func TestProblemDetails(t *testing.T) {
+	engine := New()
+	engine.ProblemDetails = true
+
+	err := NewError(http.StatusConflict, "The email is already taken", nil).WithCode("email_taken")
+	recorder := problemRequest(engine, http.MethodGet, "/items", err)
+	assert.Equal(t, MIMEApplicationProblemJSON, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, map[string]any{
+		"type":   "about:blank",
+		"title":  "Conflict",
+		"status": float64(http.StatusConflict),
+		"detail": "The email is already taken",
+		"code":   "email_taken",
+	}, decodeProblem(t, recorder))
+
+	engine = New()
+	engine.ProblemDetails = true
+	recorder = problemRequest(engine, http.MethodGet, "/missing", err)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, MIMEApplicationProblemJSON, recorder.Header().Get(HeaderContentType))
+
+	engine = New()
+	engine.ProblemDetails = true
+	recorder = problemRequest(engine, http.MethodDelete, "/items", err)
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET, HEAD", recorder.Header().Get(HeaderAllow))
+	assert.Equal(t, float64(http.StatusMethodNotAllowed), decodeProblem(t, recorder)["status"])
+}
+
+func problemRequest(engine *Engine, method, target string, err HTTPError) *httptest.ResponseRecorder {
+	engine.Route("/items").Get(func(r *http.Request) Writer { return err })
+
+	recorder := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+
+	return recorder
+}
+
+func decodeProblem(t *testing.T, recorder *httptest.ResponseRecorder) map[string]any {
+	var body map[string]any
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("Could not decode the problem response: %v", err)
+	}
+
+	return body
+}
@@ -0,0 +1,89 @@
+package wirex
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CheckRedirect returns an error unless target is a safe redirect for the request, to prevent open
+// redirects.
+//
+// Relative targets are safe, except those a browser resolves to another host, like "//evil.example" or
+// "/\evil.example". Absolute http and https targets are safe when they point to the request host or to
+// one of the RedirectHosts of the Engine.
+func CheckRedirect(r *http.Request, target string) error {
+	// Browsers ignore tabs and newlines in URLs, and treat backslashes as slashes.
+	normalized := strings.Map(func(c rune) rune {
+		switch {
+		case c == '\\':
+			return '/'
+		case c < 0x20 || c == 0x7f:
+			return -1
+		default:
+			return c
+		}
+	}, target)
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return fmt.Errorf("invalid redirect target: %w", err)
+	}
+
+	if u.Scheme == "" && u.Host == "" && !strings.HasPrefix(normalized, "//") {
+		return nil
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("redirect target %q is not an http or https URL", target)
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return nil
+	}
+
+	var hosts []string
+	if engine := engineFrom(r); engine != nil {
+		hosts = engine.RedirectHosts
+	}
+
+	if !allowedHost(u.Host, hosts) {
+		return fmt.Errorf("redirect target host %q is not allowed", u.Host)
+	}
+
+	return nil
+}
+
+// allowedHost reports whether host matches one of the hosts, which may be wildcards like "*.example.com".
+func allowedHost(host string, hosts []string) bool {
+	hostname := host
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		hostname = name
+	}
+
+	for _, allowed := range hosts {
+		if strings.EqualFold(allowed, host) || strings.EqualFold(allowed, hostname) {
+			return true
+		}
+
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok && strings.HasPrefix(suffix, ".") &&
+			len(hostname) > len(suffix) && strings.HasSuffix(strings.ToLower(hostname), strings.ToLower(suffix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RouteURL builds the path of the named route of the Engine serving the request. See Engine.URL.
+func RouteURL(r *http.Request, name string, params ...any) (string, error) {
+	engine := engineFrom(r)
+	if engine == nil {
+		return "", errors.New("WireX engine not found in the request context")
+	}
+
+	return engine.URL(name, params...)
+}
@@ -0,0 +1,36 @@
+package wirex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRedirect(t *testing.T) {
+	engine := New()
+	engine.RedirectHosts = []string{"accounts.example.net", "*.example.org"}
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/login", nil)
+	r = r.WithContext(context.WithValue(r.Context(), EngineContextKey, engine))
+
+	for target, safe := range map[string]bool{
+		"/dashboard":                       true,
+		"orders?page=2":                    true,
+		"http://example.com/home":          true,
+		"https://accounts.example.net/":    true,
+		"https://eu.example.org/":          true,
+		"https://example.org/":             false,
+		"https://evil.example/":            false,
+		"//evil.example/":                  false,
+		"/\\evil.example/":                 false,
+		"/\t/evil.example/":                false,
+		"javascript:alert(1)":              false,
+		"https://example.org.evil.example": false,
+	} {
+		err := CheckRedirect(r, target)
+		assert.Equal(t, safe, err == nil, "target %q: %v", target, err)
+	}
+}
@@ -1,28 +1,205 @@
 package wirex
 
-import "net/http"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
 
 type Route struct {
 	pattern     string
+	host        string
 	handlers    []MethodHandler
 	middlewares []Middleware
+
+	name    string
+	summary string
+	tags    []string
+	meta    map[string]any
+
+	timeout     time.Duration
+	onTimeout   Writer
+	maxBodySize int64
+
+	source string   // The file and line the route was registered from.
+	groups []string // The groups and hosts the route was added to, innermost first.
+	errs   []error  // Registration mistakes, reported by Engine.Validate.
+}
+
+// String describes the route by its pattern and the place it was registered from.
+func (r *Route) String() string {
+	origin := r.groups
+	if r.source != "" {
+		origin = append([]string{r.source}, origin...)
+	}
+
+	if len(origin) == 0 {
+		return r.host + r.pattern
+	}
+
+	return r.host + r.pattern + " (" + strings.Join(origin, ", ") + ")"
+}
+
+const RouteContextKey = "wirex-route"
+
+// RouteInfo describes a method handler registered on the Engine.
+//
+// The RouteInfo of the matched handler is stored in the request context, so middlewares and handlers
+// can make decisions per route, e.g. by its tags or metadata, without parsing the request path.
+type RouteInfo struct {
+	Name        string            // The name of the route, set with Route.Name.
+	Method      string            // The HTTP method, empty for handlers matching any method.
+	Host        string            // The host pattern, empty for routes matching any host.
+	Pattern     string            // The path pattern as registered, including wildcard constraints.
+	Constraints map[string]string // The constraints of the pattern wildcards, by wildcard name.
+	Summary     string            // A short description of the route, set with Route.Describe.
+	Tags        []string          // The tags of the route, set with Route.Tags.
+	Meta        map[string]any    // Arbitrary metadata of the route, set with Route.Meta.
+}
+
+// FromRequest extracts the RouteInfo of the handler matching the request from its context.
+//
+// Usage Example:
+//
+//	var route wirex.RouteInfo
+//	if err := route.FromRequest(r); err == nil && slices.Contains(route.Tags, "admin") {
+//		// check permissions
+//	}
+func (i *RouteInfo) FromRequest(r *http.Request) HTTPError {
+	info, ok := r.Context().Value(RouteContextKey).(RouteInfo)
+	if !ok {
+		return Error(http.StatusInternalServerError, errors.New("WireX route not found in the request context"))
+	}
+
+	*i = info
+	return nil
+}
+
+func (r *Route) info() []RouteInfo {
+	var constraints map[string]string
+	if pattern, err := parsePattern(r.pattern); err == nil {
+		for _, param := range pattern.params {
+			if param.constraint == "" {
+				continue
+			}
+
+			if constraints == nil {
+				constraints = map[string]string{}
+			}
+			constraints[param.name] = param.constraint
+		}
+	}
+
+	infos := make([]RouteInfo, 0, len(r.handlers))
+	for _, h := range r.handlers {
+		infos = append(infos, RouteInfo{
+			Name:        r.name,
+			Method:      h.method,
+			Host:        r.host,
+			Pattern:     r.pattern,
+			Constraints: constraints,
+			Summary:     r.summary,
+			Tags:        r.tags,
+			Meta:        r.meta,
+		})
+	}
+
+	return infos
+}
+
+// Name names the Route, so its URL can be built with Engine.URL, e.g. in templates. Names must be
+// unique within the Engine.
+func (r *Route) Name(name string) *Route {
+	r.name = name
+	return r
+}
+
+// Describe sets a short summary of what the Route does, for documentation and logs.
+func (r *Route) Describe(summary string) *Route {
+	r.summary = summary
+	return r
+}
+
+// Tags adds tags to the Route, like the resource or the area of the application it belongs to.
+func (r *Route) Tags(tags ...string) *Route {
+	r.tags = append(r.tags, tags...)
+	return r
+}
+
+// Meta sets a metadata value of the Route, like a required permission or a rate limit.
+func (r *Route) Meta(key string, val any) *Route {
+	if r.meta == nil {
+		r.meta = map[string]any{}
+	}
+
+	r.meta[key] = val
+	return r
+}
+
+// MaxBodySize limits the size of request bodies accepted by the Route to n bytes.
+//
+// The limit is enforced with http.MaxBytesReader before any middleware or extractor reads the body.
+// Extractors like from.Json and from.Form answer a larger body with 413 (Request Entity Too Large).
+func (r *Route) MaxBodySize(n int64) *Route {
+	r.maxBodySize = n
+	return r
 }
 
 func (r *Route) handler(method string, handler HandlerFunc) *Route {
-	for i, h := range r.handlers {
-		if h.method == method {
-			r.handlers[i].handler = Handler(handler)
-			return r
+	return r.add(MethodHandler{method: method, handler: Handler(handler)})
+}
+
+func (r *Route) add(h MethodHandler) *Route {
+	for i, existing := range r.handlers {
+		if existing.method != h.method {
+			continue
 		}
+
+		switch {
+		case h.any:
+			// Handlers for a specific method take precedence over Any.
+		case existing.any:
+			r.handlers[i] = h
+		default:
+			r.errs = append(r.errs, fmt.Errorf("%s handler is registered more than once", h.method))
+			r.handlers[i] = h
+		}
+
+		return r
 	}
 
-	r.handlers = append(r.handlers, MethodHandler{
-		method:  method,
-		handler: Handler(handler),
-	})
+	r.handlers = append(r.handlers, h)
 	return r
 }
 
+// Method adds a handler for an arbitrary HTTP method to the Route, like the WebDAV methods PROPFIND,
+// MKCOL or LOCK, or PURGE. The name is matched exactly and must be a valid HTTP token.
+func (r *Route) Method(name string, h HandlerFunc) *Route {
+	if !validMethod(name) {
+		r.errs = append(r.errs, fmt.Errorf("invalid HTTP method %q", name))
+		return r
+	}
+
+	return r.handler(name, h)
+}
+
+// validMethod reports whether the method is a token as defined by RFC 9110.
+func validMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+
+	for _, c := range method {
+		if c >= 0x80 || !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Options adds an OPTIONS method handler to the Route.
 func (r *Route) Options(h HandlerFunc) *Route {
 	return r.handler(http.MethodOptions, h)
@@ -69,6 +246,16 @@ func (r *Route) Trace(h HandlerFunc) *Route {
 }
 
 // Any handle all requests to the Route.
+// Methods with a handler of their own, registered before or after, keep using it.
 func (r *Route) Any(h HandlerFunc) *Route {
-	return r.Options(h).Get(h).Post(h).Put(h).Delete(h).Connect(h).Head(h).Patch(h).Trace(h)
+	methods := []string{
+		http.MethodOptions, http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete,
+		http.MethodConnect, http.MethodHead, http.MethodPatch, http.MethodTrace,
+	}
+
+	for _, method := range methods {
+		r.add(MethodHandler{method: method, handler: Handler(h), any: true})
+	}
+
+	return r
 }
\ No newline at end of file
@@ -2,7 +2,10 @@ package wirex
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var tests = []string{
@@ -67,4 +70,81 @@ func TesnAny(t *testing.T) {
 	if len(r.handlers) != len(tests) {
 		t.Errorf("expected %d handler for method Any, got %d", len(tests), len(r.handlers))
 	}
-}
\ No newline at end of file
+}
+
+func TestMethod(t *testing.T) {
+	engine := New()
+	engine.Route("/calendars/{name}").Method(PROPFIND, okHandler).Method(REPORT, okHandler).Get(okHandler)
+
+	assert.Equal(t, []string{PROPFIND, REPORT, http.MethodGet}, routeMethods(engine.Routes()))
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	req, _ := http.NewRequest(PROPFIND, testServer.URL+"/calendars/work", nil)
+	testRequest(t, req, http.StatusOK)
+
+	req, _ = http.NewRequest("MKCOL", testServer.URL+"/calendars/work", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Could not make MKCOL request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "GET, HEAD, PROPFIND, REPORT", resp.Header.Get(HeaderAllow))
+}
+
+func routeMethods(routes []RouteInfo) []string {
+	var methods []string
+	for _, route := range routes {
+		methods = append(methods, route.Method)
+	}
+
+	return methods
+}
+
+func TestRouteMetadata(t *testing.T) {
+	engine := New()
+
+	var seen RouteInfo
+	engine.Route("/users/{id:int}").
+		Describe("Get a user").
+		Tags("users").
+		Meta("permission", "users:read").
+		Get(func(r *http.Request) Writer {
+			if err := seen.FromRequest(r); err != nil {
+				return err
+			}
+
+			return status{http.StatusOK}
+		})
+
+	engine.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var route RouteInfo
+			if err := route.FromRequest(r); err != nil || route.Meta["permission"] != "users:read" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	testRoute(t, testServer, "/users/1", http.StatusOK)
+
+	expected := RouteInfo{
+		Method:      http.MethodGet,
+		Pattern:     "/users/{id:int}",
+		Constraints: map[string]string{"id": "int"},
+		Summary:     "Get a user",
+		Tags:        []string{"users"},
+		Meta:        map[string]any{"permission": "users:read"},
+	}
+	assert.Equal(t, expected, seen)
+	assert.Equal(t, []RouteInfo{expected}, engine.Routes())
+}
@@ -0,0 +1,263 @@
+package wirex
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// StaticOptions configures how files are served by RoutesGroup.Static.
+type StaticOptions struct {
+	Index         []string // Files looked up when a directory is requested. Defaults to "index.html".
+	Browse        bool     // Render a listing for directories without an index file.
+	Precompressed bool     // Serve a "<name>.gz" sibling to clients accepting gzip.
+	SPA           bool     // Serve the root index file for missing paths without an extension.
+}
+
+type staticFiles struct {
+	fsys  fs.FS
+	opts  StaticOptions
+	etags sync.Map
+}
+
+// Static serves the files of fsys under the given prefix.
+//
+// Files are served with a strong ETag computed from their content, Last-Modified when the file system
+// reports a modification time, and support for conditional and Range requests. Directories are answered
+// with one of the configured index files, with a listing when Browse is set, or with 404 Not Found.
+//
+// Usage Example:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	sub, _ := fs.Sub(dist, "dist")
+//	engine.Static("/", sub, wirex.StaticOptions{SPA: true, Precompressed: true})
+func (g *RoutesGroup) Static(prefix string, fsys fs.FS, opts StaticOptions) *Route {
+	if len(opts.Index) == 0 {
+		opts.Index = []string{"index.html"}
+	}
+
+	s := &staticFiles{fsys: fsys, opts: opts}
+	return g.addRoute(strings.TrimSuffix(prefix, "/")+"/{path...}", nil).Get(s.handle)
+}
+
+func (s *staticFiles) handle(r *http.Request) Writer {
+	name := r.PathValue("path")
+	isDirRequest := name == "" || strings.HasSuffix(name, "/")
+
+	name = strings.TrimSuffix(name, "/")
+	if name == "" {
+		name = "."
+	}
+
+	if !fs.ValidPath(name) {
+		return Error(http.StatusNotFound, fs.ErrNotExist)
+	}
+
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && s.opts.SPA && path.Ext(name) == "" {
+			return s.spaFallback()
+		}
+
+		return FileError(err)
+	}
+
+	if !info.IsDir() {
+		return s.file(name, info)
+	}
+
+	// Directories are always addressed with a trailing slash, so relative links inside them resolve.
+	if !isDirRequest {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			target := r.URL.EscapedPath() + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+		})
+	}
+
+	for _, index := range s.opts.Index {
+		indexName := path.Join(name, index)
+
+		if info, err := fs.Stat(s.fsys, indexName); err == nil && info.Mode().IsRegular() {
+			return s.file(indexName, info)
+		}
+	}
+
+	if s.opts.Browse {
+		return s.listing(name)
+	}
+
+	return Error(http.StatusNotFound, fs.ErrNotExist)
+}
+
+func (s *staticFiles) spaFallback() Writer {
+	name := s.opts.Index[0]
+
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil {
+		return FileError(err)
+	}
+
+	return s.file(name, info)
+}
+
+func (s *staticFiles) file(name string, info fs.FileInfo) Writer {
+	return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served, servedInfo := name, info
+
+		if s.opts.Precompressed {
+			w.Header().Add(HeaderVary, HeaderAcceptEncoding)
+
+			if acceptsGzip(r) {
+				if gzInfo, err := fs.Stat(s.fsys, name+".gz"); err == nil && gzInfo.Mode().IsRegular() {
+					served, servedInfo = name+".gz", gzInfo
+
+					contentType := mime.TypeByExtension(path.Ext(name))
+					if contentType == "" {
+						contentType = MIMEOctetStream
+					}
+
+					w.Header().Set(HeaderContentType, contentType)
+					w.Header().Set(HeaderContentEncoding, "gzip")
+				}
+			}
+		}
+
+		content, _, err := OpenFile(s.fsys, served)
+		if err != nil {
+			FileError(err).WriteResponse(w, r)
+			return
+		}
+		if closer, ok := content.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		etag, err := s.etag(served, servedInfo, content)
+		if err != nil {
+			Error(http.StatusInternalServerError, err).WriteResponse(w, r)
+			return
+		}
+
+		w.Header().Set(HeaderETag, etag)
+		http.ServeContent(w, r, name, servedInfo.ModTime(), content)
+	})
+}
+
+// etag returns a strong entity tag for the named file, hashing its content only once per
+// modification time and size.
+func (s *staticFiles) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := fmt.Sprintf("%s\x00%d\x00%d", name, info.ModTime().UnixNano(), info.Size())
+	if etag, ok := s.etags.Load(key); ok {
+		return etag.(string), nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	s.etags.Store(key, etag)
+
+	return etag, nil
+}
+
+func (s *staticFiles) listing(name string) Writer {
+	entries, err := fs.ReadDir(s.fsys, name)
+	if err != nil {
+		return FileError(err)
+	}
+
+	return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MIMETextHTMLCharsetUTF8)
+
+		var b strings.Builder
+		b.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+		for _, entry := range entries {
+			entryName := entry.Name()
+			if entry.IsDir() {
+				entryName += "/"
+			}
+
+			link := url.URL{Path: entryName}
+			fmt.Fprintf(&b, "<a href=\"%s\">%s</a>\n", html.EscapeString(link.String()), html.EscapeString(entryName))
+		}
+		b.WriteString("</pre>\n")
+
+		io.WriteString(w, b.String())
+	})
+}
+
+// OpenFile opens the named regular file of fsys as an io.ReadSeeker, buffering it in memory if the file
+// system doesn't provide seekable files. The caller closes the file if it's an io.Closer.
+func OpenFile(fsys fs.FS, name string) (io.ReadSeeker, fs.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, fs.ErrNotExist
+	}
+
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, info, nil
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return bytes.NewReader(data), info, nil
+}
+
+// FileError returns the HTTPError answering a failure to open or stat a file: 404 (Not Found) for a
+// missing file, 403 (Forbidden) for a denied one and 500 (Internal Server Error) otherwise.
+func FileError(err error) HTTPError {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return Error(http.StatusNotFound, fs.ErrNotExist)
+	case errors.Is(err, fs.ErrPermission):
+		return Error(http.StatusForbidden, fs.ErrPermission)
+	default:
+		return Error(http.StatusInternalServerError, err)
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	return negotiateEncoding(r.Header.Get(HeaderAcceptEncoding), []string{"gzip"}) == "gzip"
+}
+
+// writerFunc adapts an ordinary function to the Writer interface.
+type writerFunc func(w http.ResponseWriter, r *http.Request)
+
+func (f writerFunc) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	f(w, r)
+}
@@ -0,0 +1,122 @@
+package wirex
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var staticFS = fstest.MapFS{
+	"index.html":     {Data: []byte("<h1>index</h1>"), ModTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	"app.js":         {Data: []byte("console.log('app')")},
+	"app.js.gz":      {Data: []byte("gzipped")},
+	"docs/readme.md": {Data: []byte("# readme")},
+	"a?b/readme.md":  {Data: []byte("# query")},
+	"100%/readme.md": {Data: []byte("# percent")},
+}
+
+func newStaticServer(t *testing.T, opts StaticOptions) *httptest.Server {
+	engine := New()
+	engine.Static("/assets", staticFS, opts)
+
+	server := httptest.NewServer(engine.Handler())
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func staticGet(t *testing.T, url string, header http.Header) (*http.Response, string) {
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	// Disable transparent decompression so Content-Encoding can be checked.
+	transport := &http.Transport{DisableCompression: true}
+	client := &http.Client{Transport: transport, CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Could not make GET request to %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestStaticFile(t *testing.T) {
+	server := newStaticServer(t, StaticOptions{})
+
+	resp, body := staticGet(t, server.URL+"/assets/", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "<h1>index</h1>", body)
+	assert.NotEmpty(t, resp.Header.Get(HeaderLastModified))
+
+	etag := resp.Header.Get(HeaderETag)
+	assert.NotEmpty(t, etag)
+
+	resp, _ = staticGet(t, server.URL+"/assets/index.html", http.Header{HeaderIfNoneMatch: {etag}})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp, _ = staticGet(t, server.URL+"/assets/", http.Header{HeaderIfModifiedSince: {time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)}})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp, body = staticGet(t, server.URL+"/assets/app.js", http.Header{"Range": {"bytes=0-6"}})
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "console", body)
+
+	resp, _ = staticGet(t, server.URL+"/assets/missing.js", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = staticGet(t, server.URL+"/assets/docs", nil)
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "/assets/docs/", resp.Header.Get(HeaderLocation))
+
+	resp, _ = staticGet(t, server.URL+"/assets/docs/", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// The redirect keeps the directory name escaped.
+	resp, _ = staticGet(t, server.URL+"/assets/a%3Fb?v=1", nil)
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "/assets/a%3Fb/?v=1", resp.Header.Get(HeaderLocation))
+
+	resp, _ = staticGet(t, server.URL+"/assets/100%25", nil)
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "/assets/100%25/", resp.Header.Get(HeaderLocation))
+
+	resp, body = staticGet(t, server.URL+"/assets/100%25/readme.md", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "# percent", body)
+}
+
+func TestStaticOptions(t *testing.T) {
+	server := newStaticServer(t, StaticOptions{Browse: true, Precompressed: true, SPA: true})
+
+	resp, body := staticGet(t, server.URL+"/assets/app.js", http.Header{HeaderAcceptEncoding: {"gzip"}})
+	assert.Equal(t, "gzip", resp.Header.Get(HeaderContentEncoding))
+	assert.Equal(t, "text/javascript; charset=utf-8", resp.Header.Get(HeaderContentType))
+	assert.Equal(t, "gzipped", body)
+
+	plain, body := staticGet(t, server.URL+"/assets/app.js", nil)
+	assert.Empty(t, plain.Header.Get(HeaderContentEncoding))
+	assert.Equal(t, "console.log('app')", body)
+	assert.NotEqual(t, resp.Header.Get(HeaderETag), plain.Header.Get(HeaderETag))
+
+	_, body = staticGet(t, server.URL+"/assets/docs/", nil)
+	assert.Contains(t, body, `<a href="readme.md">readme.md</a>`)
+
+	resp, body = staticGet(t, server.URL+"/assets/users/42", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "<h1>index</h1>", body)
+
+	resp, _ = staticGet(t, server.URL+"/assets/missing.js", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
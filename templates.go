@@ -0,0 +1,193 @@
+package wirex
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Templates renders HTML pages from the html/template files of a file system.
+//
+// Every file outside the Layouts and Partials directories is a page, named by its path without the
+// extension, like "users/show". Layouts and partials are shared by all pages and named the same way, so
+// a page uses a layout with {{template "layouts/main" .}} and fills the blocks the layout defines:
+//
+//	{{template "layouts/main" .}}
+//	{{define "content"}}<h1>{{.Name}}</h1>{{end}}
+//
+// Besides Funcs, templates can call "url" to build the path of a named route, see Engine.URL:
+//
+//	<a href="{{url "user" .ID}}">{{.Name}}</a>
+//
+// Templates are parsed once, on first use, or on every render while the Engine is in debug mode, so
+// changes made on disk show up without a restart when FS is an os.DirFS.
+type Templates struct {
+	FS       fs.FS
+	Layouts  string           // The directory of layout templates, "layouts" by default.
+	Partials string           // The directory of partial templates, "partials" by default.
+	Ext      string           // The extension of template files, ".html" by default.
+	Funcs    template.FuncMap // Additional functions available to the templates.
+
+	engine *Engine // The Engine the templates are set on, for the url function and debug mode.
+	mu     sync.Mutex
+	pages  map[string]*template.Template
+}
+
+// NewTemplates returns the Templates of fsys, with the default directories and extension.
+//
+// Usage Example:
+//
+//	//go:embed views
+//	var views embed.FS
+//
+//	sub, _ := fs.Sub(views, "views")
+//	engine.Templates = wirex.NewTemplates(sub)
+func NewTemplates(fsys fs.FS) *Templates {
+	return &Templates{FS: fsys, Layouts: "layouts", Partials: "partials", Ext: ".html"}
+}
+
+// Render executes the named page with data and writes the result to w.
+func (t *Templates) Render(w io.Writer, name string, data any) error {
+	pages, err := t.load()
+	if err != nil {
+		return err
+	}
+
+	page, ok := pages[name]
+	if !ok {
+		return fmt.Errorf("template %q not found", name)
+	}
+
+	return page.ExecuteTemplate(w, name, data)
+}
+
+func (t *Templates) load() (map[string]*template.Template, error) {
+	if t.engine != nil && t.engine.Debug {
+		return t.parse()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pages == nil {
+		pages, err := t.parse()
+		if err != nil {
+			return nil, err
+		}
+		t.pages = pages
+	}
+
+	return t.pages, nil
+}
+
+func (t *Templates) parse() (map[string]*template.Template, error) {
+	funcs := template.FuncMap{"url": t.url}
+	for name, fn := range t.Funcs {
+		funcs[name] = fn
+	}
+
+	shared := template.New("").Funcs(funcs)
+	var pages []string
+
+	err := fs.WalkDir(t.FS, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(file) != t.Ext {
+			return nil
+		}
+
+		if inDir(file, t.Layouts) || inDir(file, t.Partials) {
+			return t.parseFile(shared, file)
+		}
+
+		pages = append(pages, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*template.Template, len(pages))
+	for _, file := range pages {
+		page, err := shared.Clone()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := t.parseFile(page, file); err != nil {
+			return nil, err
+		}
+
+		result[strings.TrimSuffix(file, t.Ext)] = page
+	}
+
+	return result, nil
+}
+
+func (t *Templates) parseFile(set *template.Template, file string) error {
+	content, err := fs.ReadFile(t.FS, file)
+	if err != nil {
+		return err
+	}
+
+	_, err = set.New(strings.TrimSuffix(file, t.Ext)).Parse(string(content))
+	return err
+}
+
+func (t *Templates) url(name string, params ...any) (string, error) {
+	if t.engine == nil {
+		return "", errors.New("templates are not set on an Engine")
+	}
+
+	return t.engine.URL(name, params...)
+}
+
+func inDir(file, dir string) bool {
+	return dir != "" && strings.HasPrefix(file, dir+"/")
+}
+
+// WriteHTML renders the named page of the Engine's Templates and writes it with the given status,
+// setting Content-Length and the content type, unless a Content-Type header is already set.
+//
+// The page is rendered into a buffer before anything is written, so a template error is answered with
+// 500 (Internal Server Error) and returned.
+func WriteHTML(w http.ResponseWriter, r *http.Request, status int, name string, data any) error {
+	engine := engineFrom(r)
+	if engine == nil || engine.Templates == nil {
+		err := errors.New("no Templates set on the Engine")
+		Error(http.StatusInternalServerError, err).WriteResponse(w, r)
+		return err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := engine.Templates.Render(buf, name, data); err != nil {
+		err = fmt.Errorf("render template %q: %w", name, err)
+		Error(http.StatusInternalServerError, err).WriteResponse(w, r)
+		return err
+	}
+
+	header := w.Header()
+	if header.Get(HeaderContentType) == "" {
+		header.Set(HeaderContentType, MIMETextHTMLCharsetUTF8)
+	}
+	header.Set(HeaderContentLength, strconv.Itoa(buf.Len()))
+
+	w.WriteHeader(status)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.Error("cannot write html to response", "error", err)
+	}
+
+	return nil
+}
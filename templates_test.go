@@ -0,0 +1,91 @@
+package wirex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplates(t *testing.T) {
+	views := fstest.MapFS{
+		"layouts/main.html":  {Data: []byte(`<title>{{block "title" .}}App{{end}}</title>{{template "partials/nav" .}}<main>{{block "content" .}}{{end}}</main>`)},
+		"partials/nav.html":  {Data: []byte(`<a href="{{url "user" .ID}}">{{.Name}}</a>`)},
+		"users/show.html":    {Data: []byte(`{{template "layouts/main" .}}{{define "content"}}<h1>{{.Name}}</h1>{{end}}`)},
+		"users/private.html": {Data: []byte(`{{template "layouts/main" .}}{{define "title"}}Private{{end}}`)},
+	}
+
+	engine := New()
+	engine.Templates = NewTemplates(views)
+	engine.Route("/users/{id:int}").Get(htmlPage("users/show")).Name("user")
+	engine.Route("/private").Get(htmlPage("users/private"))
+	engine.Route("/missing").Get(htmlPage("users/missing"))
+
+	handler := engine.Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, MIMETextHTMLCharsetUTF8, recorder.Header().Get(HeaderContentType))
+	assert.Equal(t, `<title>App</title><a href="/users/7">Ann &amp; Bob</a><main><h1>Ann &amp; Bob</h1></main>`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/private", nil))
+	assert.Equal(t, `<title>Private</title><a href="/users/7">Ann &amp; Bob</a><main></main>`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	// Templates are cached, unless the Engine is in debug mode.
+	views["users/show.html"] = &fstest.MapFile{Data: []byte(`{{template "layouts/main" .}}{{define "content"}}changed{{end}}`)}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	assert.NotContains(t, recorder.Body.String(), "changed")
+
+	engine.Debug = true
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	assert.Contains(t, recorder.Body.String(), "<main>changed</main>")
+}
+
+func htmlPage(name string) HandlerFunc {
+	return func(r *http.Request) Writer {
+		return writerFunc(func(w http.ResponseWriter, r *http.Request) {
+			WriteHTML(w, r, http.StatusOK, name, struct {
+				ID   int
+				Name string
+			}{7, "Ann & Bob"})
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	engine := New()
+	engine.Route("/users/{id:int}/files/{path...}").Get(okHandler).Name("file")
+	engine.Route("/").Get(okHandler).Name("home")
+
+	path, err := engine.URL("file", 42, "docs/a b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/42/files/docs/a%20b.txt", path)
+
+	path, err = engine.URL("home")
+	assert.NoError(t, err)
+	assert.Equal(t, "/", path)
+
+	_, err = engine.URL("file", "me", "a.txt")
+	assert.ErrorContains(t, err, "constraint")
+
+	_, err = engine.URL("file", 42)
+	assert.ErrorContains(t, err, "2 wildcards, got 1 params")
+
+	_, err = engine.URL("missing")
+	assert.ErrorContains(t, err, `no route named "missing"`)
+
+	engine.Route("/home").Get(okHandler).Name("home")
+	assert.ErrorContains(t, engine.Validate(), `name "home" is already used by route /`)
+}
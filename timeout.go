@@ -0,0 +1,163 @@
+package wirex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Timeout sets the time the Route has to produce a response.
+//
+// The handler runs with a request context whose deadline is d from the moment the route is matched, so
+// extractors and the code they call can give up early. If the deadline passes first, the client gets
+// a 503 (Service Unavailable) HTTPError, or the onTimeout writer when given, and anything the handler
+// writes afterwards is discarded. The response is buffered until the handler returns, so the timeout is
+// not meant for streaming responses.
+//
+// Usage Example:
+//
+//	engine.Route("/reports/{id}").Get(report).Timeout(60 * time.Second)
+func (r *Route) Timeout(d time.Duration, onTimeout ...Writer) *Route {
+	r.timeout = d
+	r.onTimeout = nil
+	if len(onTimeout) > 0 {
+		r.onTimeout = onTimeout[0]
+	}
+
+	return r
+}
+
+// Timeout sets the timeout of the routes in the group that don't have one of their own. See Route.Timeout.
+func (g *RoutesGroup) Timeout(d time.Duration, onTimeout ...Writer) {
+	for _, route := range g.routes {
+		if route.timeout == 0 {
+			route.Timeout(d, onTimeout...)
+		}
+	}
+}
+
+func timeout(d time.Duration, onTimeout Writer) Middleware {
+	if onTimeout == nil {
+		onTimeout = Error(http.StatusServiceUnavailable, errors.New("request timed out"))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			r = r.WithContext(ctx)
+			tw := &timeoutWriter{header: make(http.Header)}
+
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+
+			go func() {
+				defer func() {
+					p := recover()
+					if p == nil {
+						return
+					}
+
+					// http.ErrAbortHandler is recognized by its identity, anything else takes its stack along.
+					if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+						panicked <- p
+						return
+					}
+
+					panicked <- &recoveredPanic{value: p, stack: debug.Stack()}
+				}()
+
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+
+				header := w.Header()
+				for key, values := range tw.header {
+					header[key] = values
+				}
+
+				if tw.status == 0 {
+					tw.status = http.StatusOK
+				}
+
+				w.WriteHeader(tw.status)
+				w.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				tw.timedOut = true
+				tw.mu.Unlock()
+
+				onTimeout.WriteResponse(w, r)
+			}
+		})
+	}
+}
+
+// recoveredPanic is a panic recovered in a handler goroutine and raised again in the one serving the
+// request, along with the stack of the goroutine it happened in. Recover reports that stack.
+type recoveredPanic struct {
+	value any
+	stack []byte
+}
+
+func (p *recoveredPanic) Error() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+// Unwrap returns the panic value if it's an error.
+func (p *recoveredPanic) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
+
+// timeoutWriter buffers the response of a handler running under a timeout.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+
+	return tw.body.Write(b)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+
+	tw.status = code
+}
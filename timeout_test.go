@@ -0,0 +1,102 @@
+package wirex
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func slowHandler(r *http.Request) Writer {
+	select {
+	case <-time.After(time.Second):
+		return text("done")
+	case <-r.Context().Done():
+		return text("cancelled")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	engine := New()
+
+	reports := NewRoutesGroup()
+	reports.Route("/slow").Get(slowHandler).Timeout(5 * time.Second)
+	reports.Route("/fast").Get(func(r *http.Request) Writer {
+		_, ok := r.Context().Deadline()
+		assert.True(t, ok)
+
+		return text("fast")
+	})
+	reports.Timeout(20 * time.Millisecond)
+
+	engine.Group("/reports", reports)
+	engine.Route("/limited").Get(slowHandler).Timeout(20*time.Millisecond, status{http.StatusGatewayTimeout})
+	engine.Route("/default").Get(slowHandler).Timeout(20 * time.Millisecond)
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	testBody(t, testServer, "/reports/fast", http.StatusOK, "fast")
+	testRoute(t, testServer, "/limited", http.StatusGatewayTimeout)
+
+	resp, err := http.Get(testServer.URL + "/default")
+	if err != nil {
+		t.Fatalf("Could not make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.NotContains(t, string(body), "cancelled")
+
+	for _, route := range engine.routes {
+		if route.pattern == "/reports/slow" {
+			assert.Equal(t, 5*time.Second, route.timeout)
+		}
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	handler := timeout(time.Second, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	defer func() {
+		p := recover()
+		recovered, ok := p.(*recoveredPanic)
+		if !assert.True(t, ok, "panic value %v", p) {
+			return
+		}
+
+		assert.Equal(t, "boom", recovered.value)
+		assert.Contains(t, string(recovered.stack), "timeout_test.go")
+	}()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestTimeoutRecover(t *testing.T) {
+	engine := New()
+	engine.Debug = true
+
+	engine.Route("/panic").Get(func(r *http.Request) Writer { panic("boom") }).Timeout(time.Second)
+	engine.Route("/abort").Get(func(r *http.Request) Writer { panic(http.ErrAbortHandler) }).Timeout(time.Second)
+	engine.Use(Recover())
+
+	handler := engine.Handler()
+
+	message, causes := recoverMessage(t, handler, "/panic")
+	assert.Equal(t, http.StatusText(http.StatusInternalServerError), message)
+	if assert.Len(t, causes, 1) {
+		assert.Contains(t, causes[0], "panic: boom")
+		// The stack is the handler's, not the one of the goroutine the panic was raised again in.
+		assert.Contains(t, causes[0], "TestTimeoutRecover.func1")
+	}
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+}
@@ -0,0 +1,76 @@
+package wirex
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL builds the path of the route with the given name, filling its wildcards with params in order.
+// Params are formatted with fmt.Sprint and escaped, and must satisfy the constraints of their wildcards.
+// The value of a multi-segment wildcard, like {path...}, keeps its slashes.
+//
+// Usage Example:
+//
+//	engine.Route("/users/{id:int}").Get(showUser).Name("user")
+//
+//	path, err := engine.URL("user", 42) // "/users/42"
+func (e *Engine) URL(name string, params ...any) (string, error) {
+	var route *Route
+	for _, r := range e.routes {
+		if r.name == name {
+			route = r
+			break
+		}
+	}
+	if route == nil {
+		return "", fmt.Errorf("no route named %q", name)
+	}
+
+	pattern, err := parsePattern(route.pattern)
+	if err != nil {
+		return "", err
+	}
+	if len(params) != len(pattern.params) {
+		return "", fmt.Errorf("route %q has %d wildcards, got %d params", name, len(pattern.params), len(params))
+	}
+
+	var b strings.Builder
+	path, i := pattern.path, 0
+	for path != "" {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			b.WriteString(path)
+			break
+		}
+
+		end := start + strings.IndexByte(path[start:], '}') + 1
+		b.WriteString(path[:start])
+		wildcard := path[start:end]
+		path = path[end:]
+
+		if wildcard == "{$}" {
+			continue
+		}
+
+		param, value := pattern.params[i], fmt.Sprint(params[i])
+		i++
+
+		if param.check != nil && !param.check(value) {
+			return "", fmt.Errorf("route %q: param %q doesn't satisfy the constraint of wildcard %s", name, value, param.name)
+		}
+
+		if !param.multi {
+			b.WriteString(url.PathEscape(value))
+			continue
+		}
+
+		segments := strings.Split(value, "/")
+		for j, segment := range segments {
+			segments[j] = url.PathEscape(segment)
+		}
+		b.WriteString(strings.Join(segments, "/"))
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,141 @@
+package wirex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Validate checks all routes of the Engine before they are registered.
+//
+// It reports every problem found instead of stopping at the first: patterns that can't be parsed,
+// invalid methods, handlers registered twice for the same method of a route, route names used twice,
+// routes matching exactly the same requests, and routes http.ServeMux rejects as conflicting. Each route
+// in the returned error is described along with the file, group and host it came from.
+//
+// Usage Example:
+//
+//	if err := engine.Validate(); err != nil {
+//		log.Fatal(err)
+//	}
+func (e *Engine) Validate() error {
+	var errs []error
+
+	for _, route := range e.routes {
+		for _, err := range route.errs {
+			errs = append(errs, fmt.Errorf("route %s: %w", route, err))
+		}
+	}
+
+	named := map[string]*Route{}
+	for _, route := range e.routes {
+		if route.name == "" {
+			continue
+		}
+
+		if other, ok := named[route.name]; ok {
+			errs = append(errs, fmt.Errorf("route %s: name %q is already used by route %s", route, route.name, other))
+			continue
+		}
+		named[route.name] = route
+	}
+
+	keys, endpoints, routeErrs := e.endpoints()
+	errs = append(errs, routeErrs...)
+
+	for _, key := range keys {
+		errs = append(errs, duplicates(endpoints[key])...)
+	}
+
+	// Register one pattern per key in a scratch mux, so http.ServeMux reports the conflicts it would
+	// panic on, and find out which of the earlier patterns each rejected one conflicts with.
+	mux := http.NewServeMux()
+	var registered []endpoint
+
+	for _, key := range keys {
+		ep := endpoints[key][0]
+
+		err := tryHandle(mux, ep.pattern, http.NotFoundHandler())
+		if err == nil {
+			registered = append(registered, ep)
+			continue
+		}
+
+		conflicting := false
+		for _, other := range registered {
+			if conflicts(other.pattern, ep.pattern) {
+				errs = append(errs, fmt.Errorf("route %s conflicts with route %s", ep, other))
+				conflicting = true
+			}
+		}
+
+		if !conflicting {
+			errs = append(errs, fmt.Errorf("route %s: %w", ep, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// duplicates reports endpoints sharing a http.ServeMux pattern that can never be reached, because an
+// earlier endpoint matches the same requests.
+func duplicates(endpoints []endpoint) []error {
+	var errs []error
+
+	for i := range endpoints {
+		for j := 0; j < i; j++ {
+			if endpoints[i].signature() == endpoints[j].signature() {
+				errs = append(errs, fmt.Errorf("route %s duplicates route %s", endpoints[i], endpoints[j]))
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// signature identifies the requests an endpoint matches among those matched by its http.ServeMux pattern.
+func (ep endpoint) signature() string {
+	var b strings.Builder
+	if ep.host != nil {
+		b.WriteString(ep.route.host)
+	}
+
+	for _, param := range ep.params {
+		b.WriteString("\x00" + param.constraint)
+	}
+
+	return b.String()
+}
+
+func (ep endpoint) String() string {
+	if ep.method == "" {
+		return ep.route.String()
+	}
+
+	return ep.method + " " + ep.route.String()
+}
+
+// conflicts reports whether http.ServeMux rejects the two patterns together.
+func conflicts(a, b string) bool {
+	mux := http.NewServeMux()
+	if tryHandle(mux, a, http.NotFoundHandler()) != nil {
+		return false
+	}
+
+	return tryHandle(mux, b, http.NotFoundHandler()) != nil
+}
+
+// tryHandle registers the pattern in mux, turning the panic of an invalid or conflicting pattern into an error.
+// http.ServeMux checks a pattern before changing its state, so a rejected pattern leaves it intact.
+func tryHandle(mux *http.ServeMux, pattern string, handler http.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	mux.Handle(pattern, handler)
+	return nil
+}
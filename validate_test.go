@@ -0,0 +1,52 @@
+package wirex
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	engine := New()
+	engine.Route("/users/{id}").Get(okHandler)
+	engine.Route("/check").Any(okHandler).Post(errHandler)
+
+	assert.NoError(t, engine.Validate())
+
+	users := NewRoutesGroup()
+	users.Route("/{name}").Get(okHandler)
+	users.Route("/{id:int}").Get(okHandler).Get(errHandler)
+	engine.Group("/users", users)
+
+	engine.Route("/{section}/me").Get(okHandler)
+	engine.Route("/files").Method("BAD METHOD", okHandler)
+	engine.Route("/files/{name:(}").Get(okHandler)
+	engine.Route("files").Get(okHandler)
+
+	err := engine.Validate()
+	assert.Error(t, err)
+
+	message := err.Error()
+	assert.Regexp(t, `route GET /users/\{name\} \(validate_test.go:\d+, group /users\) duplicates route GET /users/\{id\} \(validate_test.go:\d+\)`, message)
+	assert.Regexp(t, `route /users/\{id:int\} \(validate_test.go:\d+, group /users\): GET handler is registered more than once`, message)
+	assert.Regexp(t, `route GET /\{section\}/me \(validate_test.go:\d+\) conflicts with route GET /users/\{id\}`, message)
+	assert.Regexp(t, `route /files \(validate_test.go:\d+\): invalid HTTP method "BAD METHOD"`, message)
+	assert.Regexp(t, `route /files/\{name:\(\} \(validate_test.go:\d+\): pattern`, message)
+	assert.Regexp(t, `route files \(validate_test.go:\d+\): pattern "files" must begin with a slash`, message)
+	assert.NotContains(t, message, "/check")
+
+	assert.Panics(t, func() { engine.Handler() })
+}
+
+func TestAnyPrecedence(t *testing.T) {
+	r := &Route{}
+	r.Get(okHandler).Any(errHandler)
+
+	assert.Empty(t, r.errs)
+	assert.Len(t, r.handlers, len(tests))
+
+	for _, h := range r.handlers {
+		assert.Equal(t, h.method != http.MethodGet, h.any, h.method)
+	}
+}
@@ -0,0 +1,719 @@
+package wirex
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// WSMessageType is the type of a WebSocket data message.
+type WSMessageType int
+
+const (
+	WSText   WSMessageType = wsOpText
+	WSBinary WSMessageType = wsOpBinary
+)
+
+// WebSocket close codes, see RFC 6455: https://datatracker.ietf.org/doc/html/rfc6455#section-7.4.1
+const (
+	WSCloseNormal          = 1000
+	WSCloseGoingAway       = 1001
+	WSCloseProtocolError   = 1002
+	WSCloseUnsupportedData = 1003
+	WSCloseNoStatus        = 1005
+	WSCloseAbnormal        = 1006
+	WSCloseInvalidPayload  = 1007
+	WSClosePolicyViolation = 1008
+	WSCloseMessageTooBig   = 1009
+	WSCloseInternalError   = 1011
+)
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+)
+
+const (
+	// DefaultWSReadLimit is the maximum size of a message read from a WebSocket, unless set otherwise.
+	DefaultWSReadLimit = 1 << 20
+
+	wsGUID         = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsCompressMin  = 128 // Smaller messages are sent uncompressed, as compressing them gains little.
+	wsCloseTimeout = time.Second
+	wsDeflateTail  = "\x00\x00\xff\xff"
+)
+
+// ErrWSClosed is returned when writing to a WebSocket after its close frame was sent.
+var ErrWSClosed = errors.New("websocket: connection closed")
+
+// WSCloseError is the error returned by WSConn.ReadMessage once the connection is closed by the client,
+// or because the client violated the protocol.
+type WSCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WSCloseError) Error() string {
+	if e.Reason == "" {
+		return "websocket: close " + strconv.Itoa(e.Code)
+	}
+
+	return "websocket: close " + strconv.Itoa(e.Code) + ": " + e.Reason
+}
+
+// WebSocketOptions configures the WebSocket handshake and connection.
+type WebSocketOptions struct {
+	// CheckOrigin reports whether the Origin of the handshake request is allowed. By default, requests
+	// without an Origin header and requests whose Origin host matches the Host header are allowed.
+	CheckOrigin func(r *http.Request) bool
+
+	Subprotocols []string // The subprotocols supported by the server, in order of preference.
+	ReadLimit    int64    // The maximum size of a message, in bytes. DefaultWSReadLimit when zero.
+	Compression  bool     // Negotiate the permessage-deflate extension with clients supporting it.
+}
+
+// WebSocket is a Writer upgrading the request to a WebSocket connection and running Handler with it.
+// The connection is closed when Handler returns.
+//
+// The upgrade takes over the connection with http.ResponseController, so it works behind middlewares
+// forwarding Unwrap, like the ResponseWriter of Logger and Recover, but not behind Route.Timeout.
+type WebSocket struct {
+	Handler func(conn *WSConn)
+	Options WebSocketOptions
+}
+
+var _ Writer = &WebSocket{}
+
+func (ws *WebSocket) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	conn, err := Upgrade(w, r, ws.Options)
+	if err != nil {
+		return
+	}
+	defer conn.Close(WSCloseNormal, "")
+
+	ws.Handler(conn)
+}
+
+// WebSocket adds a GET method handler to the Route, upgrading requests to WebSocket connections.
+//
+// Usage Example:
+//
+//	engine.Route("/echo").WebSocket(func(conn *wirex.WSConn) {
+//		for {
+//			typ, message, err := conn.ReadMessage()
+//			if err != nil {
+//				return
+//			}
+//			conn.WriteMessage(typ, message)
+//		}
+//	})
+func (r *Route) WebSocket(handler func(conn *WSConn), opts ...WebSocketOptions) *Route {
+	ws := &WebSocket{Handler: handler}
+	if len(opts) > 0 {
+		ws.Options = opts[0]
+	}
+
+	return r.Get(func(*http.Request) Writer { return ws })
+}
+
+// Upgrade performs the WebSocket handshake described in RFC 6455 and returns the connection.
+//
+// If the request isn't a valid handshake, isn't allowed by CheckOrigin or the connection can't be
+// taken over, Upgrade answers the request with an HTTPError and returns it. Headers already set on w,
+// like cookies, are sent with the handshake response.
+func Upgrade(w http.ResponseWriter, r *http.Request, opts WebSocketOptions) (*WSConn, error) {
+	fail := func(status int, err error) (*WSConn, error) {
+		httpErr := Error(status, fmt.Errorf("websocket: %w", err))
+		httpErr.WriteResponse(w, r)
+		return nil, httpErr
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set(HeaderAllow, http.MethodGet)
+		return fail(http.StatusMethodNotAllowed, errors.New("handshake method is not GET"))
+	}
+	if !headerHasToken(r.Header, HeaderConnection, "upgrade") || !headerHasToken(r.Header, HeaderUpgrade, "websocket") {
+		return fail(http.StatusBadRequest, errors.New("not a websocket handshake"))
+	}
+	if r.Header.Get(HeaderSecWebSocketVersion) != "13" {
+		w.Header().Set(HeaderSecWebSocketVersion, "13")
+		return fail(http.StatusUpgradeRequired, errors.New("unsupported version"))
+	}
+
+	key := r.Header.Get(HeaderSecWebSocketKey)
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return fail(http.StatusBadRequest, errors.New("invalid Sec-WebSocket-Key"))
+	}
+
+	checkOrigin := opts.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		return fail(http.StatusForbidden, errors.New("origin not allowed"))
+	}
+
+	subprotocol := selectSubprotocol(r, opts.Subprotocols)
+	compress := opts.Compression && offersDeflate(r)
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	b.WriteString(HeaderSecWebSocketAccept + ": " + acceptKey(key) + "\r\n")
+	if subprotocol != "" {
+		b.WriteString(HeaderSecWebSocketProtocol + ": " + subprotocol + "\r\n")
+	}
+	if compress {
+		b.WriteString(HeaderSecWebSocketExtensions + ": permessage-deflate; server_no_context_takeover; client_no_context_takeover\r\n")
+	}
+	w.Header().Write(&b)
+	b.WriteString("\r\n")
+
+	// The server may have set deadlines for reading the request, which don't apply to the connection.
+	netConn.SetDeadline(time.Time{})
+	if _, err := netConn.Write(b.Bytes()); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	readLimit := opts.ReadLimit
+	if readLimit <= 0 {
+		readLimit = DefaultWSReadLimit
+	}
+
+	return &WSConn{
+		conn:        netConn,
+		br:          brw.Reader,
+		request:     r,
+		subprotocol: subprotocol,
+		compress:    compress,
+		readLimit:   readLimit,
+	}, nil
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get(HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func selectSubprotocol(r *http.Request, supported []string) string {
+	for _, protocol := range supported {
+		if headerHasToken(r.Header, HeaderSecWebSocketProtocol, protocol) {
+			return protocol
+		}
+	}
+
+	return ""
+}
+
+// offersDeflate reports whether the client offers a permessage-deflate configuration the server can
+// accept. Contexts are never kept between messages, and the server always uses the full window.
+func offersDeflate(r *http.Request) bool {
+	for _, value := range r.Header.Values(HeaderSecWebSocketExtensions) {
+	offers:
+		for _, offer := range strings.Split(value, ",") {
+			params := strings.Split(offer, ";")
+			if strings.TrimSpace(params[0]) != "permessage-deflate" {
+				continue
+			}
+
+			for _, param := range params[1:] {
+				name, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				switch strings.TrimSpace(name) {
+				case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+				case "server_max_window_bits":
+					if strings.Trim(strings.TrimSpace(val), `"`) != "15" {
+						continue offers
+					}
+				default:
+					continue offers
+				}
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// WSConn is a WebSocket connection.
+//
+// ReadMessage must be called from one goroutine at a time, and it answers pings and close frames by
+// itself. The write methods are safe for concurrent use.
+type WSConn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	request     *http.Request
+	subprotocol string
+	compress    bool
+	readLimit   int64
+	reading     atomic.Bool
+
+	writeMu   sync.Mutex
+	closeSent bool
+
+	pongHandler atomic.Pointer[func([]byte)]
+}
+
+// Request returns the handshake request.
+func (c *WSConn) Request() *http.Request {
+	return c.request
+}
+
+// Subprotocol returns the subprotocol negotiated during the handshake, if any.
+func (c *WSConn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// RemoteAddr returns the network address of the client.
+func (c *WSConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadLimit sets the maximum size of a message, in bytes. A larger message closes the connection
+// with WSCloseMessageTooBig.
+func (c *WSConn) SetReadLimit(n int64) {
+	c.readLimit = n
+}
+
+// SetReadDeadline sets the deadline for reading messages. It's typically extended by a pong handler
+// to detect dead connections.
+func (c *WSConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for writing messages.
+func (c *WSConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// SetPongHandler sets the function called with the payload of the pongs received by ReadMessage.
+func (c *WSConn) SetPongHandler(h func(data []byte)) {
+	c.pongHandler.Store(&h)
+}
+
+// ReadMessage reads the next data message, assembling its fragments and decompressing it.
+//
+// It returns a *WSCloseError once the client closes the connection or violates the protocol, in which
+// case the connection is closed with the corresponding code. Any other error closes the connection too.
+func (c *WSConn) ReadMessage() (WSMessageType, []byte, error) {
+	c.reading.Store(true)
+	defer c.reading.Store(false)
+
+	var (
+		typ        WSMessageType
+		message    []byte
+		compressed bool
+	)
+
+	for {
+		f, err := c.readFrame(c.readLimit - int64(len(message)))
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+
+		if f.rsv1 && (!c.compress || f.opcode != wsOpText && f.opcode != wsOpBinary) {
+			return 0, nil, c.fail(wsProtocolError("unexpected compressed frame"))
+		}
+
+		switch f.opcode {
+		case wsOpPing:
+			if err := c.writeRaw(appendFrame(nil, wsOpPong, false, f.payload)); err != nil && !errors.Is(err, ErrWSClosed) {
+				return 0, nil, c.fail(err)
+			}
+			continue
+		case wsOpPong:
+			if h := c.pongHandler.Load(); h != nil {
+				(*h)(f.payload)
+			}
+			continue
+		case wsOpClose:
+			return 0, nil, c.closeReceived(f.payload)
+		case wsOpContinuation:
+			if typ == 0 {
+				return 0, nil, c.fail(wsProtocolError("unexpected continuation frame"))
+			}
+		case wsOpText, wsOpBinary:
+			if typ != 0 {
+				return 0, nil, c.fail(wsProtocolError("expected continuation frame"))
+			}
+			typ, compressed = WSMessageType(f.opcode), f.rsv1
+		default:
+			return 0, nil, c.fail(wsProtocolError("unknown opcode " + strconv.Itoa(int(f.opcode))))
+		}
+
+		message = append(message, f.payload...)
+		if !f.fin {
+			continue
+		}
+
+		if compressed {
+			if message, err = inflate(message, c.readLimit); err != nil {
+				return 0, nil, c.fail(err)
+			}
+		}
+
+		if typ == WSText && !utf8.Valid(message) {
+			return 0, nil, c.fail(&WSCloseError{Code: WSCloseInvalidPayload, Reason: "invalid UTF-8"})
+		}
+
+		return typ, message, nil
+	}
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v.
+func (c *WSConn) ReadJSON(v any) error {
+	_, message, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(message, v)
+}
+
+// WriteMessage sends a data message, compressed if permessage-deflate was negotiated.
+func (c *WSConn) WriteMessage(typ WSMessageType, data []byte) error {
+	if typ != WSText && typ != WSBinary {
+		return fmt.Errorf("websocket: invalid message type %d", typ)
+	}
+
+	frame, err := prepareFrame(typ, data, c.compress)
+	if err != nil {
+		return err
+	}
+
+	return c.writeRaw(frame)
+}
+
+// WriteJSON encodes v as JSON and sends it as a text message.
+func (c *WSConn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.WriteMessage(WSText, data)
+}
+
+// Ping sends a ping with the given payload of at most 125 bytes. The client answers with a pong,
+// passed to the pong handler.
+func (c *WSConn) Ping(data []byte) error {
+	if len(data) > 125 {
+		return errors.New("websocket: control frame payload too long")
+	}
+
+	return c.writeRaw(appendFrame(nil, wsOpPing, false, data))
+}
+
+// Close sends a close frame with the code and reason, and closes the connection. When no message is
+// being read, the client's close frame is awaited briefly first, as the closing handshake requires.
+func (c *WSConn) Close(code int, reason string) error {
+	if !c.sendClose(code, reason) {
+		return c.conn.Close()
+	}
+
+	if c.reading.Load() {
+		// ReadMessage gets the client's close frame and closes the connection.
+		return c.conn.SetReadDeadline(time.Now().Add(wsCloseTimeout))
+	}
+
+	c.conn.SetReadDeadline(time.Now().Add(wsCloseTimeout))
+	for {
+		f, err := c.readFrame(c.readLimit)
+		if err != nil || f.opcode == wsOpClose {
+			break
+		}
+	}
+
+	return c.conn.Close()
+}
+
+// sendClose sends a close frame, unless one was sent already, and reports whether it did.
+func (c *WSConn) sendClose(code int, reason string) bool {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return false
+	}
+	c.closeSent = true
+
+	var payload []byte
+	if code != WSCloseNoStatus {
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(wsCloseTimeout))
+	c.conn.Write(appendFrame(nil, wsOpClose, false, payload))
+
+	return true
+}
+
+func (c *WSConn) closeReceived(payload []byte) error {
+	closeErr := &WSCloseError{Code: WSCloseNoStatus}
+
+	switch {
+	case len(payload) == 1:
+		return c.fail(wsProtocolError("invalid close frame"))
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+
+		if !validCloseCode(closeErr.Code) {
+			return c.fail(wsProtocolError("invalid close code"))
+		}
+		if !utf8.ValidString(closeErr.Reason) {
+			return c.fail(&WSCloseError{Code: WSCloseInvalidPayload, Reason: "invalid UTF-8"})
+		}
+	}
+
+	// The client's close code is echoed, which completes the closing handshake.
+	c.sendClose(closeErr.Code, "")
+	c.conn.Close()
+
+	return closeErr
+}
+
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011, code >= 3000 && code <= 4999:
+		return true
+	default:
+		return false
+	}
+}
+
+// fail closes the connection after a read error, with the close code of a *WSCloseError.
+func (c *WSConn) fail(err error) error {
+	var closeErr *WSCloseError
+	if errors.As(err, &closeErr) {
+		c.sendClose(closeErr.Code, closeErr.Reason)
+	}
+
+	c.conn.Close()
+	return err
+}
+
+func (c *WSConn) writeRaw(frame []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return ErrWSClosed
+	}
+
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func wsProtocolError(reason string) error {
+	return &WSCloseError{Code: WSCloseProtocolError, Reason: reason}
+}
+
+type wsFrame struct {
+	fin     bool
+	rsv1    bool
+	opcode  byte
+	payload []byte
+}
+
+// readFrame reads a client frame, whose payload is masked, refusing data frames larger than limit.
+func (c *WSConn) readFrame(limit int64) (wsFrame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return wsFrame{}, err
+	}
+
+	f := wsFrame{fin: head[0]&0x80 != 0, rsv1: head[0]&0x40 != 0, opcode: head[0] & 0x0f}
+	if head[0]&0x30 != 0 {
+		return f, wsProtocolError("reserved bits set")
+	}
+	if head[1]&0x80 == 0 {
+		return f, wsProtocolError("unmasked client frame")
+	}
+
+	n := int64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return f, err
+		}
+		n = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return f, err
+		}
+		if ext[0]&0x80 != 0 {
+			return f, wsProtocolError("invalid payload length")
+		}
+		n = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	if f.opcode >= wsOpClose {
+		if !f.fin || n > 125 {
+			return f, wsProtocolError("invalid control frame")
+		}
+	} else if n > limit {
+		return f, &WSCloseError{Code: WSCloseMessageTooBig, Reason: "message too big"}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return f, err
+	}
+
+	f.payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, f.payload); err != nil {
+		return f, err
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+
+	return f, nil
+}
+
+// appendFrame appends a final, unmasked server frame to buf.
+func appendFrame(buf []byte, opcode byte, rsv1 bool, payload []byte) []byte {
+	b0 := 0x80 | opcode
+	if rsv1 {
+		b0 |= 0x40
+	}
+
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, b0, byte(n))
+	case n <= 0xffff:
+		buf = binary.BigEndian.AppendUint16(append(buf, b0, 126), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint64(append(buf, b0, 127), uint64(n))
+	}
+
+	return append(buf, payload...)
+}
+
+// prepareFrame returns the frame of a data message, compressed if compress is set and the message is
+// large enough.
+func prepareFrame(typ WSMessageType, data []byte, compress bool) ([]byte, error) {
+	if !compress || len(data) < wsCompressMin {
+		return appendFrame(make([]byte, 0, len(data)+10), byte(typ), false, data), nil
+	}
+
+	compressed, err := deflate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendFrame(make([]byte, 0, len(compressed)+10), byte(typ), true, compressed), nil
+}
+
+var (
+	flateWriters sync.Pool
+	flateReaders sync.Pool
+)
+
+// deflate compresses a message without context takeover, as described in RFC 7692.
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fw, _ := flateWriters.Get().(*flate.Writer)
+	if fw == nil {
+		var err error
+		if fw, err = flate.NewWriter(&buf, flate.BestSpeed); err != nil {
+			return nil, err
+		}
+	} else {
+		fw.Reset(&buf)
+	}
+	defer flateWriters.Put(fw)
+
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Flush(); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte(wsDeflateTail)), nil
+}
+
+// inflate decompresses a message, refusing to produce more than limit bytes.
+func inflate(data []byte, limit int64) ([]byte, error) {
+	// The flush marker stripped by the sender, followed by a final empty block ending the stream.
+	src := io.MultiReader(bytes.NewReader(data), strings.NewReader(wsDeflateTail+"\x01\x00\x00\xff\xff"))
+
+	fr, _ := flateReaders.Get().(io.ReadCloser)
+	if fr == nil {
+		fr = flate.NewReader(src)
+	} else {
+		fr.(flate.Resetter).Reset(src, nil)
+	}
+	defer flateReaders.Put(fr)
+
+	message, err := io.ReadAll(io.LimitReader(fr, limit+1))
+	if err != nil {
+		return nil, &WSCloseError{Code: WSCloseInvalidPayload, Reason: "invalid compressed data"}
+	}
+	if int64(len(message)) > limit {
+		return nil, &WSCloseError{Code: WSCloseMessageTooBig, Reason: "message too big"}
+	}
+
+	return message, nil
+}
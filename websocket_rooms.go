@@ -0,0 +1,130 @@
+package wirex
+
+import (
+	"sync"
+)
+
+// WSRooms groups WebSocket connections into named rooms, to broadcast messages to all the members of a
+// room. It's safe for concurrent use.
+//
+// Usage Example:
+//
+//	rooms := wirex.NewWSRooms()
+//
+//	engine.Route("/chat/{room}").WebSocket(func(conn *wirex.WSConn) {
+//		room := conn.Request().PathValue("room")
+//		rooms.Join(room, conn)
+//		defer rooms.Leave(room, conn)
+//
+//		for {
+//			typ, message, err := conn.ReadMessage()
+//			if err != nil {
+//				return
+//			}
+//			rooms.Broadcast(room, typ, message)
+//		}
+//	})
+type WSRooms struct {
+	mu    sync.RWMutex
+	rooms map[string]map[*WSConn]struct{}
+}
+
+// NewWSRooms returns an empty set of rooms.
+func NewWSRooms() *WSRooms {
+	return &WSRooms{rooms: map[string]map[*WSConn]struct{}{}}
+}
+
+// Join adds the connection to the room.
+func (r *WSRooms) Join(room string, conn *WSConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	members, ok := r.rooms[room]
+	if !ok {
+		members = map[*WSConn]struct{}{}
+		r.rooms[room] = members
+	}
+
+	members[conn] = struct{}{}
+}
+
+// Leave removes the connection from the room.
+func (r *WSRooms) Leave(room string, conn *WSConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.leave(room, conn)
+}
+
+// LeaveAll removes the connection from all the rooms it joined.
+func (r *WSRooms) LeaveAll(conn *WSConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for room := range r.rooms {
+		r.leave(room, conn)
+	}
+}
+
+func (r *WSRooms) leave(room string, conn *WSConn) {
+	delete(r.rooms[room], conn)
+	if len(r.rooms[room]) == 0 {
+		delete(r.rooms, room)
+	}
+}
+
+// Len returns the number of connections in the room.
+func (r *WSRooms) Len(room string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.rooms[room])
+}
+
+// Broadcast sends the message to all the members of the room, concurrently, and returns once every
+// member got it or failed. Members the message can't be sent to are removed from all rooms.
+func (r *WSRooms) Broadcast(room string, typ WSMessageType, data []byte) error {
+	r.mu.RLock()
+	members := make([]*WSConn, 0, len(r.rooms[room]))
+	for conn := range r.rooms[room] {
+		members = append(members, conn)
+	}
+	r.mu.RUnlock()
+
+	// The frames are built once, for the members with and without compression.
+	var frames [2][]byte
+	for _, conn := range members {
+		i := 0
+		if conn.compress {
+			i = 1
+		}
+
+		if frames[i] == nil {
+			frame, err := prepareFrame(typ, data, conn.compress)
+			if err != nil {
+				return err
+			}
+			frames[i] = frame
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, conn := range members {
+		frame := frames[0]
+		if conn.compress {
+			frame = frames[1]
+		}
+
+		wg.Add(1)
+		go func(conn *WSConn) {
+			defer wg.Done()
+
+			if err := conn.writeRaw(frame); err != nil {
+				r.LeaveAll(conn)
+			}
+		}(conn)
+	}
+	wg.Wait()
+
+	return nil
+}
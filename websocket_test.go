@@ -0,0 +1,236 @@
+package wirex
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocketEcho(t *testing.T) {
+	engine := New()
+	engine.Route("/echo").WebSocket(echo, WebSocketOptions{Compression: true, ReadLimit: 1024})
+	engine.Use(Logger())
+
+	server := httptest.NewServer(engine.Handler())
+	defer server.Close()
+
+	client := dialWebSocket(t, server, "/echo", "Sec-WebSocket-Extensions: permessage-deflate; client_max_window_bits\r\n")
+	defer client.conn.Close()
+	assert.Contains(t, client.resp.Header.Get(HeaderSecWebSocketExtensions), "permessage-deflate")
+
+	client.writeFrame(wsOpText, false, false, []byte("hel"))
+	client.writeFrame(wsOpPing, true, false, []byte("ping"))
+	client.writeFrame(wsOpContinuation, true, false, []byte("lo"))
+
+	opcode, payload := client.readFrame(t)
+	assert.Equal(t, byte(wsOpPong), opcode)
+	assert.Equal(t, "ping", string(payload))
+
+	opcode, payload = client.readFrame(t)
+	assert.Equal(t, byte(wsOpText), opcode)
+	assert.Equal(t, "hello", string(payload))
+
+	long := strings.Repeat("compressible ", 50)
+	compressed, _ := deflate([]byte(long))
+	client.writeFrame(wsOpText, true, true, compressed)
+
+	opcode, payload = client.readFrame(t)
+	assert.Equal(t, byte(wsOpText), opcode)
+	assert.Equal(t, long, string(payload))
+
+	client.writeFrame(wsOpBinary, true, false, make([]byte, 2048))
+	opcode, payload = client.readFrame(t)
+	assert.Equal(t, byte(wsOpClose), opcode)
+	assert.Equal(t, WSCloseMessageTooBig, int(binary.BigEndian.Uint16(payload)))
+}
+
+func TestWebSocketClose(t *testing.T) {
+	closed := make(chan error, 1)
+
+	engine := New()
+	engine.Route("/ws").WebSocket(func(conn *WSConn) {
+		_, _, err := conn.ReadMessage()
+		closed <- err
+	})
+
+	server := httptest.NewServer(engine.Handler())
+	defer server.Close()
+
+	client := dialWebSocket(t, server, "/ws", "")
+	defer client.conn.Close()
+
+	client.writeFrame(wsOpClose, true, false, binary.BigEndian.AppendUint16(nil, WSCloseGoingAway))
+
+	opcode, payload := client.readFrame(t)
+	assert.Equal(t, byte(wsOpClose), opcode)
+	assert.Equal(t, WSCloseGoingAway, int(binary.BigEndian.Uint16(payload)))
+	assert.Equal(t, &WSCloseError{Code: WSCloseGoingAway}, <-closed)
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	engine := New()
+	engine.Route("/ws").WebSocket(echo)
+
+	handler := engine.Handler()
+
+	request := func(header map[string]string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/ws", nil)
+		for key, val := range map[string]string{
+			HeaderConnection:          "keep-alive, Upgrade",
+			HeaderUpgrade:             "websocket",
+			HeaderSecWebSocketVersion: "13",
+			HeaderSecWebSocketKey:     "dGhlIHNhbXBsZSBub25jZQ==",
+		} {
+			req.Header.Set(key, val)
+		}
+		for key, val := range header {
+			req.Header.Set(key, val)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, request(map[string]string{HeaderOrigin: "https://evil.example"}))
+	assert.Equal(t, http.StatusUpgradeRequired, request(map[string]string{HeaderSecWebSocketVersion: "8"}))
+	assert.Equal(t, http.StatusBadRequest, request(map[string]string{HeaderUpgrade: "h2c"}))
+	assert.Equal(t, http.StatusBadRequest, request(map[string]string{HeaderSecWebSocketKey: "short"}))
+
+	// The recorder can't be hijacked, so a valid handshake fails afterwards.
+	assert.Equal(t, http.StatusInternalServerError, request(map[string]string{HeaderOrigin: "http://example.com"}))
+
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestWSRooms(t *testing.T) {
+	rooms := NewWSRooms()
+	joined := make(chan struct{})
+
+	engine := New()
+	engine.Route("/rooms/{room}").WebSocket(func(conn *WSConn) {
+		room := conn.Request().PathValue("room")
+		rooms.Join(room, conn)
+		defer rooms.Leave(room, conn)
+
+		joined <- struct{}{}
+		conn.ReadMessage()
+	})
+
+	server := httptest.NewServer(engine.Handler())
+	defer server.Close()
+
+	first := dialWebSocket(t, server, "/rooms/a", "")
+	defer first.conn.Close()
+	second := dialWebSocket(t, server, "/rooms/a", "")
+	defer second.conn.Close()
+	<-joined
+	<-joined
+
+	assert.Equal(t, 2, rooms.Len("a"))
+	assert.NoError(t, rooms.Broadcast("a", WSText, []byte("hi")))
+
+	for _, client := range []*wsClient{first, second} {
+		opcode, payload := client.readFrame(t)
+		assert.Equal(t, byte(wsOpText), opcode)
+		assert.Equal(t, "hi", string(payload))
+	}
+}
+
+func echo(conn *WSConn) {
+	for {
+		typ, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		conn.WriteMessage(typ, message)
+	}
+}
+
+type wsClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+	resp *http.Response
+}
+
+func dialWebSocket(t *testing.T, server *httptest.Server, path, extraHeaders string) *wsClient {
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Could not dial the test server: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: "+server.Listener.Addr().String()+"\r\n"+
+		"Connection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+extraHeaders+"\r\n")
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("Could not read the handshake response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected status code %d for the handshake, got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+
+	return &wsClient{conn: conn, br: br, resp: resp}
+}
+
+func (c *wsClient) writeFrame(opcode byte, fin, rsv1 bool, payload []byte) {
+	frame := appendFrame(nil, opcode, rsv1, payload)
+	if !fin {
+		frame[0] &^= 0x80
+	}
+
+	// Client frames are masked, with the key between the header and the payload.
+	headerLen := len(frame) - len(payload)
+	mask := []byte{1, 2, 3, 4}
+	masked := append(append(append([]byte{}, frame[:headerLen]...), mask...), payload...)
+	masked[1] |= 0x80
+	for i := range payload {
+		masked[headerLen+4+i] ^= mask[i%4]
+	}
+
+	c.conn.Write(masked)
+}
+
+func (c *wsClient) readFrame(t *testing.T) (byte, []byte) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		t.Fatalf("Could not read a frame: %v", err)
+	}
+
+	n := int(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(c.br, ext[:])
+		n = int(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	payload := make([]byte, n)
+	io.ReadFull(c.br, payload)
+
+	if head[0]&0x40 != 0 {
+		inflated, err := inflate(payload, 1<<20)
+		if err != nil {
+			t.Fatalf("Could not inflate a frame: %v", err)
+		}
+		payload = inflated
+	}
+
+	return head[0] & 0x0f, payload
+}
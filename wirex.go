@@ -1,10 +1,15 @@
 package wirex
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -17,6 +22,34 @@ type Engine struct {
 	Validator *validator.Validate // Used for validating request data in request extractors like from.Json.
 	Debug     bool                // A flag indicating if the Engine is in debug mode.
 
+	PrettyJSON bool // Indent the JSON responses written while the Engine is in debug mode.
+
+	Templates *Templates // The HTML templates rendered by WriteHTML and write.HTML.
+
+	// RedirectHosts lists the hosts the redirects to client-provided targets may point to besides the
+	// request host, like "accounts.example.com" or "*.example.com". See CheckRedirect.
+	RedirectHosts []string
+
+	// ErrorHandler, when set, receives every error returned by a handler, including the HTTPErrors of
+	// request extractors, and returns the Writer used to respond instead. It's the place to map domain
+	// errors to statuses, redact messages or report errors. Returning nil keeps the error's own response.
+	// Errors are not logged by the Engine when ErrorHandler is set.
+	ErrorHandler func(r *http.Request, err error) Writer
+
+	// ProblemDetails makes RFC 9457 problem details the shape of the errors written by the Engine: the
+	// DefaultHTTPErrors returned by extractors and handlers are written as a Problem, and so are the
+	// 404 (Not Found) and 405 (Method Not Allowed) responses for unmatched requests.
+	ProblemDetails bool
+
+	TrailingSlash   TrailingSlash // How requests differing from a route only by a trailing slash are handled.
+	CaseInsensitive bool          // Match route paths regardless of letter case.
+	MergeSlashes    bool          // Collapse repeated slashes in request paths before matching, instead of redirecting.
+
+	methods          []string         // The methods of all routes, used to tell 404 from 405 for unmatched requests.
+	groups           []*endpointGroup // The endpoints of every http.ServeMux pattern, in registration order.
+	fallbacks        sync.Map         // The http.ServeMux used by fallThrough, by rejected patterns.
+	pre              []Middleware
+	handler          http.Handler // The pre middlewares wrapped around the mux, built with the routes.
 	routesRegistered bool
 }
 
@@ -29,8 +62,6 @@ func New() *Engine {
 		routesRegistered: false,
 	}
 
-	engine.With(EngineContextKey, engine)
-
 	return engine
 }
 
@@ -57,24 +88,351 @@ func (e *Engine) FromRequest(r *http.Request) HTTPError {
 	return nil
 }
 
-func (e *Engine) route(pattern string, handlers []MethodHandler, middlewares []Middleware) {
-	for _, handler := range handlers {
-		route := pattern
+// engineFrom returns the Engine serving the request, or nil if the request didn't come through one.
+func engineFrom(r *http.Request) *Engine {
+	engine, _ := r.Context().Value(EngineContextKey).(*Engine)
+	return engine
+}
+
+// Routes returns a description of every method handler registered on the Engine, in registration order.
+func (e *Engine) Routes() []RouteInfo {
+	var infos []RouteInfo
+	for _, route := range e.routes {
+		infos = append(infos, route.info()...)
+	}
+
+	return infos
+}
+
+// endpoint is a method handler of a route, ready to be served.
+type endpoint struct {
+	route   *Route
+	method  string
+	pattern string       // The http.ServeMux pattern of the endpoint.
+	host    *hostPattern // Set for hosts with wildcard labels, which http.ServeMux can't match.
+	params  []pathParam
+	handler http.Handler
+}
+
+// constrained reports whether the endpoint must check a request before handling it.
+func (ep *endpoint) constrained() bool {
+	if ep.host != nil {
+		return true
+	}
+
+	for _, param := range ep.params {
+		if param.check != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match checks the request against the host pattern and wildcard constraints of the endpoint.
+// The wildcards are the names under which http.ServeMux stored the path values of the request.
+func (ep *endpoint) match(r *http.Request, wildcards []pathParam) (*http.Request, bool) {
+	values := make([]string, len(ep.params))
+	for i, param := range ep.params {
+		values[i] = r.PathValue(wildcards[i].name)
+
+		if param.check != nil && !param.check(values[i]) {
+			return nil, false
+		}
+	}
+
+	if ep.host != nil {
+		hostValues, ok := ep.host.match(r.Host)
+		if !ok {
+			return nil, false
+		}
+
+		r = withHostValues(r, hostValues)
+	}
+
+	for i, param := range ep.params {
+		if param.name != wildcards[i].name {
+			r.SetPathValue(param.name, values[i])
+		}
+	}
+
+	return r, true
+}
+
+func (e *Engine) route(route *Route, endpoints map[string][]endpoint) (keys []string, err error) {
+	if !strings.HasPrefix(route.pattern, "/") {
+		return nil, fmt.Errorf("pattern %q must begin with a slash", route.pattern)
+	}
+
+	host := parseHostPattern(route.host)
+
+	pattern, err := parsePattern(route.pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.TrailingSlash != TrailingSlashDefault {
+		pattern = exactSlash(pattern)
+	}
+
+	if e.CaseInsensitive {
+		pattern.path, pattern.shape = lowerLiterals(pattern.path), strings.ToLower(pattern.shape)
+	}
+
+	infos := route.info()
+
+	var middlewares []Middleware
+	if route.maxBodySize > 0 {
+		middlewares = append(middlewares, maxBodySize(route.maxBodySize))
+	}
+
+	middlewares = append(middlewares, route.middlewares...)
+
+	if route.timeout > 0 {
+		middlewares = append(middlewares, timeout(route.timeout, route.onTimeout))
+	}
+
+	for i, handler := range route.handlers {
+		key, muxPattern := pattern.shape, pattern.path
+		if host == nil {
+			key, muxPattern = route.host+key, route.host+muxPattern
+		}
+
 		// In the case of Any route
 		if handler.method != "" {
-			route = handler.method + " " + pattern
+			key, muxPattern = handler.method+" "+key, handler.method+" "+muxPattern
+		}
+
+		if _, ok := endpoints[key]; !ok {
+			keys = append(keys, key)
 		}
 
-		e.mux.Handle(route, applyMiddlewares(handler.handler, middlewares...))
+		endpoints[key] = append(endpoints[key], endpoint{
+			route:   route,
+			method:  handler.method,
+			pattern: muxPattern,
+			host:    host,
+			params:  pattern.params,
+			// The Engine and the route are stored in the context first, so every middleware can reach them.
+			handler: with(EngineContextKey, e)(with(RouteContextKey, infos[i])(applyMiddlewares(handler.handler, middlewares...))),
+		})
 	}
+
+	return keys, nil
 }
 
-func (e *Engine) registerRoutes() {
+// endpoints groups the method handlers of all routes by the requests they match. The keys are
+// returned in registration order, along with the routes that couldn't be translated.
+func (e *Engine) endpoints() (keys []string, endpoints map[string][]endpoint, errs []error) {
+	endpoints = map[string][]endpoint{}
+
 	for _, route := range e.routes {
-		e.route(route.pattern, route.handlers, route.middlewares)
+		routeKeys, err := e.route(route, endpoints)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("route %s: %w", route, err))
+			continue
+		}
+
+		keys = append(keys, routeKeys...)
+	}
+
+	return keys, endpoints, errs
+}
+
+func (e *Engine) registerRoutes() {
+	keys, endpoints, _ := e.endpoints()
+
+	for _, key := range keys {
+		method := endpoints[key][0].method
+		if method != "" && !slices.Contains(e.methods, method) {
+			e.methods = append(e.methods, method)
+		}
+	}
+
+	if slices.Contains(e.methods, http.MethodGet) && !slices.Contains(e.methods, http.MethodHead) {
+		e.methods = append(e.methods, http.MethodHead)
+	}
+	slices.Sort(e.methods)
+
+	handlers := make(map[string]http.Handler, len(keys))
+	for _, key := range keys {
+		first := endpoints[key][0]
+
+		handlers[key] = e.restoreURL(first.params, e.dispatch(key, endpoints[key]))
+		e.mux.Handle(first.pattern, handlers[key])
+
+		e.groups = append(e.groups, &endpointGroup{key: key, pattern: first.pattern, params: first.params, serve: serveFirst(endpoints[key])})
+	}
+
+	if e.TrailingSlash != TrailingSlashDefault {
+		e.registerTrailingSlashes(keys, endpoints, handlers)
+	}
+
+	if e.Templates != nil {
+		e.Templates.engine = e
+	}
+
+	e.handler = applyMiddlewares(http.HandlerFunc(e.serveMux), e.pre...)
+	e.routesRegistered = true
+}
+
+// registerTrailingSlashes registers the other form of every route pattern, handled according to the
+// TrailingSlash policy. Forms that are registered as routes themselves are left alone.
+func (e *Engine) registerTrailingSlashes(keys []string, endpoints map[string][]endpoint, handlers map[string]http.Handler) {
+	for _, key := range keys {
+		otherKey, ok := toggleSlash(key)
+		if !ok {
+			continue
+		}
+
+		pattern := endpoints[key][0].pattern
+		otherPattern, _ := toggleSlash(pattern)
+
+		if e.TrailingSlash == TrailingSlashStrict {
+			// Only a pattern with a trailing slash needs its other form to be blocked,
+			// otherwise http.ServeMux redirects to it. That holds for every method.
+			if !strings.HasSuffix(pattern, "/{$}") {
+				continue
+			}
+
+			if _, path, ok := strings.Cut(otherKey, " "); ok {
+				otherKey = path
+				_, otherPattern, _ = strings.Cut(otherPattern, " ")
+			}
+		}
+
+		if _, ok := endpoints[otherKey]; ok {
+			continue
+		}
+
+		// A derived pattern may conflict with a registered one, it's left out in that case.
+		_ = tryHandle(e.mux, otherPattern, e.restoreURL(endpoints[key][0].params, e.trailingSlashHandler(handlers[key])))
+	}
+}
+
+// dispatch returns the handler for all endpoints sharing a http.ServeMux pattern. Endpoints that are
+// constrained by a host pattern or typed wildcards are tried in registration order, then the request
+// falls back to the endpoint without constraints. Validate reports keys with more than one of those.
+// A request none of them accepts falls through to the other patterns matching it, see fallThrough.
+func (e *Engine) dispatch(key string, endpoints []endpoint) http.Handler {
+	if len(endpoints) == 1 && !endpoints[0].constrained() {
+		return endpoints[0].handler
+	}
+
+	serve := serveFirst(endpoints)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !serve(w, r) {
+			e.fallThrough(w, r, key)
+		}
+	})
+}
+
+// serveFirst returns a function serving a request with the first of the endpoints that accepts it,
+// which reports whether one did.
+func serveFirst(endpoints []endpoint) func(w http.ResponseWriter, r *http.Request) bool {
+	var fallback *endpoint
+	var constrained []endpoint
+
+	for i, endpoint := range endpoints {
+		if endpoint.constrained() {
+			constrained = append(constrained, endpoint)
+			continue
+		}
+
+		if fallback == nil {
+			fallback = &endpoints[i]
+		}
+	}
+
+	// All endpoints share the wildcards of the first one, which is registered in http.ServeMux.
+	wildcards := endpoints[0].params
+
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		for _, endpoint := range constrained {
+			if r, ok := endpoint.match(r, wildcards); ok {
+				endpoint.handler.ServeHTTP(w, r)
+				return true
+			}
+		}
+
+		if fallback == nil {
+			return false
+		}
+
+		r, _ = fallback.match(r, wildcards)
+		fallback.handler.ServeHTTP(w, r)
+		return true
 	}
 }
 
+// endpointGroup is the endpoints sharing a http.ServeMux pattern, kept to route the requests falling
+// through their constraints.
+type endpointGroup struct {
+	key     string
+	pattern string
+	params  []pathParam
+	serve   func(w http.ResponseWriter, r *http.Request) bool
+}
+
+type rejectedKey struct{}
+
+// fallThrough serves a request whose endpoints all rejected it with the most specific of the other
+// patterns matching it, as if the rejected patterns weren't registered. So with "/users/{id:int}" and
+// "/users/{rest...}", "/users/bob" is served by the latter. The request is answered with 404 (Not
+// Found) once no pattern is left.
+func (e *Engine) fallThrough(w http.ResponseWriter, r *http.Request, key string) {
+	// Match against the normalized request, like the first time.
+	r = e.normalize(r)
+
+	rejected := []string{key}
+	for {
+		mux := e.fallbackMux(rejected)
+		if _, pattern := mux.Handler(r); pattern == "" {
+			e.notFound(w, r)
+			return
+		}
+
+		var next string
+		mux.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), rejectedKey{}, &next)))
+		if next == "" {
+			return
+		}
+
+		rejected = append(rejected, next)
+	}
+}
+
+// fallbackMux returns a http.ServeMux with the patterns of all endpoint groups but the rejected ones.
+// Its handlers report a group rejecting the request through the rejectedKey context value instead
+// of falling through themselves.
+func (e *Engine) fallbackMux(rejected []string) *http.ServeMux {
+	sorted := slices.Clone(rejected)
+	slices.Sort(sorted)
+
+	id := strings.Join(sorted, "\n")
+	if mux, ok := e.fallbacks.Load(id); ok {
+		return mux.(*http.ServeMux)
+	}
+
+	mux := http.NewServeMux()
+	for _, group := range e.groups {
+		if slices.Contains(rejected, group.key) {
+			continue
+		}
+
+		key, serve := group.key, group.serve
+		mux.Handle(group.pattern, e.restoreURL(group.params, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !serve(w, r) {
+				*r.Context().Value(rejectedKey{}).(*string) = key
+			}
+		})))
+	}
+
+	stored, _ := e.fallbacks.LoadOrStore(id, mux)
+	return stored.(*http.ServeMux)
+}
+
 // ServeHTTP implements the http.Handler interface for the Engine.
 //
 // This method logs the HTTP request method and URL path using slog and then delegates the handling
@@ -87,33 +445,81 @@ func (e *Engine) registerRoutes() {
 //	http.ListenAndServe(":8080", nil)
 func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Get request:", r.Method, r.URL.Path)
+
+	if e.handler == nil {
+		applyMiddlewares(http.HandlerFunc(e.serveMux), e.pre...).ServeHTTP(w, r)
+		return
+	}
+
+	e.handler.ServeHTTP(w, r)
+}
+
+func (e *Engine) serveMux(w http.ResponseWriter, r *http.Request) {
+	r = e.normalize(r)
+
+	// http.ServeMux answers unmatched requests in plain text, find them beforehand for problem details.
+	if e.ProblemDetails {
+		if _, pattern := e.mux.Handler(r); pattern == "" {
+			e.unmatched(w, r)
+			return
+		}
+	}
+
 	e.mux.ServeHTTP(w, r)
 }
 
+// Pre adds middlewares that run for every request before it's matched against the routes.
+//
+// Unlike middlewares added with Use, which wrap the routes registered so far, Pre middlewares see every
+// request, including the ones ending in 404 (Not Found), and may change what is matched, like the request
+// method or path. They must be added before the Engine handler is built.
+//
+// Usage Example:
+//
+//	engine.Pre(wirex.MethodOverride())
+func (e *Engine) Pre(middleware ...Middleware) {
+	e.pre = append(e.pre, middleware...)
+}
+
 // Handler returns the http.Handler for the Engine.
 //
 // This method ensures that all routes are registered before returning the Engine as an http.Handler.
 // This is useful for integrating the Engine with other HTTP servers or middleware that require an http.Handler.
+// The routes are checked with Validate first, and Handler panics with the error listing every problem found.
 //
 // Usage Example:
 //
 //	handler := e.Handler()
 //	http.Handle("/", handler)
 func (e *Engine) Handler() http.Handler {
-	if !e.routesRegistered {
-		e.registerRoutes()
+	if err := e.build(); err != nil {
+		panic(err)
 	}
 
 	return e
 }
 
+// build checks the routes with Validate and registers them, unless they're already registered.
+func (e *Engine) build() error {
+	if e.routesRegistered {
+		return nil
+	}
+
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	e.registerRoutes()
+	return nil
+}
+
 // ListenAndServe starts an HTTP server with the specified address using the Engine's handler.
 //
 // This method logs the server's listening state on the specified address and starts an HTTP server.
 // It uses the http.ListenAndServe function along with the Engine's handler. Any errors occurring during
 // the server's operation are logged upon exiting the function.
 //
-// Returns an error if the server fails to start or encounters issues during runtime.
+// Returns an error if the routes don't pass Validate, or if the server fails to start or encounters issues during runtime.
 //
 // Parameters:
 // - addr string: The address for the server to listen and serve.
@@ -127,7 +533,11 @@ func (e *Engine) ListenAndServe(addr string) (err error) {
 	slog.Info("Listening and serving HTTP", "addr", addr)
 	defer func() { slog.Error(err.Error()) }()
 
-	err = http.ListenAndServe(addr, e.Handler())
+	if err = e.build(); err != nil {
+		return
+	}
+
+	err = http.ListenAndServe(addr, e)
 	return
 }
 
@@ -151,7 +561,11 @@ func (engine *Engine) ListenAndServeTLS(addr, certFile, keyFile string) (err err
 	slog.Info("Listening and serving HTTPS", "addr", addr)
 	defer func() { slog.Error(err.Error()) }()
 
-	err = http.ListenAndServeTLS(addr, certFile, keyFile, engine.Handler())
+	if err = engine.build(); err != nil {
+		return
+	}
+
+	err = http.ListenAndServeTLS(addr, certFile, keyFile, engine)
 	return
 }
 
@@ -175,6 +589,10 @@ func (engine *Engine) Serve(listener net.Listener) (err error) {
 	slog.Info("Listening and serving HTTP on listener what's bind with address", "addr", listener.Addr())
 	defer func() { slog.Error(err.Error()) }()
 
-	err = http.Serve(listener, engine.Handler())
+	if err = engine.build(); err != nil {
+		return
+	}
+
+	err = http.Serve(listener, engine)
 	return
 }
@@ -2,6 +2,8 @@ package wirex
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -111,4 +113,116 @@ func testPostRoute(t *testing.T, server *httptest.Server, path string, expectedS
 	if resp.StatusCode != expectedStatus {
 		t.Errorf("Expected status code %d for path %s, got %d", expectedStatus, path, resp.StatusCode)
 	}
-}
\ No newline at end of file
+}
+
+func TestMethodOverride(t *testing.T) {
+	engine := New()
+	engine.Pre(MethodOverride())
+
+	engine.Route("/users/{id}").Put(okHandler).Post(errHandler).Get(errHandler)
+	engine.Route("/names").MaxBodySize(1 << 10).Put(func(r *http.Request) Writer {
+		if err := r.ParseForm(); err != nil {
+			return Error(http.StatusRequestEntityTooLarge, err)
+		}
+
+		return text(r.PostFormValue("name"))
+	})
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, testServer.URL+"/users/1", nil)
+	req.Header.Set(HeaderXHTTPMethodOverride, "put")
+	testRequest(t, req, http.StatusOK)
+
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/users/1", strings.NewReader("_method=PUT&name=bob"))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	testRequest(t, req, http.StatusOK)
+
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/users/1", strings.NewReader("_method=CONNECT"))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	testRequest(t, req, http.StatusInternalServerError)
+
+	req, _ = http.NewRequest(http.MethodGet, testServer.URL+"/users/1", nil)
+	req.Header.Set(HeaderXHTTPMethodOverride, http.MethodPut)
+	testRequest(t, req, http.StatusInternalServerError)
+
+	// Multipart forms aren't parsed, and neither are large urlencoded ones.
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/users/1", strings.NewReader("--b\r\nContent-Disposition: form-data; name=\"_method\"\r\n\r\nPUT\r\n--b--\r\n"))
+	req.Header.Set(HeaderContentType, MIMEMultipartForm+"; boundary=b")
+	testRequest(t, req, http.StatusInternalServerError)
+
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/users/1", strings.NewReader("_method=PUT&name="+strings.Repeat("a", 64<<10)))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	testRequest(t, req, http.StatusInternalServerError)
+
+	// The body is left for the handler, within the route's size limit.
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/names", strings.NewReader("_method=PUT&name=bob"))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Could not make POST request to /names: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "bob", string(body))
+
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/names", strings.NewReader("_method=PUT&name="+strings.Repeat("a", 2<<10)))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	testRequest(t, req, http.StatusRequestEntityTooLarge)
+}
+
+func testRequest(t *testing.T, req *http.Request, expectedStatus int) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Could not make %s request to %s: %v", req.Method, req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		t.Errorf("Expected status code %d for %s %s, got %d", expectedStatus, req.Method, req.URL.Path, resp.StatusCode)
+	}
+}
+
+var errForbidden = errors.New("forbidden")
+
+type domainError struct {
+	status
+	err error
+}
+
+func (e domainError) Error() string {
+	return e.err.Error()
+}
+
+func TestErrorHandler(t *testing.T) {
+	engine := New()
+
+	engine.Route("/forbidden").Get(func(r *http.Request) Writer {
+		return domainError{status{http.StatusInternalServerError}, errForbidden}
+	})
+	engine.Route("/other").Get(func(r *http.Request) Writer {
+		return Error(http.StatusBadRequest, errors.New("bad input"))
+	})
+	engine.Route("/ok").Get(okHandler)
+
+	var handled []string
+	engine.ErrorHandler = func(r *http.Request, err error) Writer {
+		handled = append(handled, r.URL.Path)
+
+		if de, ok := err.(domainError); ok && de.err == errForbidden {
+			return status{http.StatusForbidden}
+		}
+
+		return nil
+	}
+
+	testServer := httptest.NewServer(engine.Handler())
+	defer testServer.Close()
+
+	testRoute(t, testServer, "/forbidden", http.StatusForbidden)
+	testRoute(t, testServer, "/other", http.StatusBadRequest)
+	testRoute(t, testServer, "/ok", http.StatusOK)
+
+	assert.Equal(t, []string{"/forbidden", "/other"}, handled)
+}
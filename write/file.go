@@ -0,0 +1,98 @@
+package write
+
+import (
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/bridgex-eu/wirex"
+)
+
+// FileData represents a file served with http.ServeContent, which detects its content type and answers
+// Range, If-None-Match and If-Modified-Since requests with 206, 416 and 304 as appropriate.
+type FileData struct {
+	FS          fs.FS         // The file system Name is opened from, unless Content is set.
+	Name        string        // The name of the file, used for its content type and Content-Disposition.
+	Content     io.ReadSeeker // The content served instead of opening Name, if set. Closed once served.
+	ModTime     time.Time     // The modification time of Content, ignored when it's not set.
+	Disposition string        // "inline" or "attachment", no Content-Disposition when empty.
+}
+
+var _ wirex.Writer = &FileData{}
+
+// WriteResponse serves the file, and closes it, or Content, if it's an io.Closer. A missing file is
+// answered with 404 (Not Found), and a file that can't be read with 403 (Forbidden) or 500 (Internal
+// Server Error).
+func (f *FileData) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	content, modTime := f.Content, f.ModTime
+	if content == nil {
+		opened, info, err := wirex.OpenFile(f.FS, f.Name)
+		if err != nil {
+			wirex.FileError(err).WriteResponse(w, r)
+			return
+		}
+
+		content, modTime = opened, info.ModTime()
+	}
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	name := path.Base(filepath.ToSlash(f.Name))
+
+	header := w.Header()
+	if f.Disposition != "" {
+		header.Set(wirex.HeaderContentDisposition, mime.FormatMediaType(f.Disposition, map[string]string{"filename": name}))
+	}
+
+	if header.Get(wirex.HeaderETag) == "" && !modTime.IsZero() {
+		size, err := content.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = content.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			slog.Error("cannot seek file", "name", f.Name, "error", err)
+			wirex.Error(http.StatusInternalServerError, err).WriteResponse(w, r)
+			return
+		}
+
+		header.Set(wirex.HeaderETag, `"`+strconv.FormatInt(modTime.UnixNano(), 36)+"-"+strconv.FormatInt(size, 36)+`"`)
+	}
+
+	http.ServeContent(w, r, name, modTime, content)
+}
+
+// File serves the file at the given path of the operating system, inline.
+//
+// Usage Example:
+//
+//	return write.File(filepath.Join(reportsDir, id+".pdf"))
+func File(name string, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&FileData{FS: os.DirFS(filepath.Dir(name)), Name: filepath.Base(name), Disposition: "inline"}, other...)
+}
+
+// FS serves the named file of fsys, inline.
+func FS(fsys fs.FS, name string, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&FileData{FS: fsys, Name: name, Disposition: "inline"}, other...)
+}
+
+// Attachment serves the content as a download saved under the given file name, and closes it if it's
+// an io.Closer. The modification time is used for conditional requests, unless it's zero.
+//
+// Usage Example:
+//
+//	f, err := os.Open(export.Path)
+//	if err != nil {
+//		return wirex.Error(http.StatusInternalServerError, err)
+//	}
+//	return write.Attachment("export-2024.csv", f, export.CreatedAt)
+func Attachment(name string, content io.ReadSeeker, modTime time.Time, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&FileData{Name: name, Content: content, ModTime: modTime, Disposition: "attachment"}, other...)
+}
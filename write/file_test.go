@@ -0,0 +1,122 @@
+package write
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/bridgex-eu/wirex"
+	"github.com/stretchr/testify/assert"
+)
+
+var reportModTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+var reportFS = fstest.MapFS{
+	"report.txt": {Data: []byte("0123456789"), ModTime: reportModTime},
+}
+
+func fileRequest(header ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	return req
+}
+
+func TestFS(t *testing.T) {
+	recorder := serve(fileRequest(), FS(reportFS, "report.txt"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "0123456789", recorder.Body.String())
+	assert.Equal(t, `inline; filename=report.txt`, recorder.Header().Get(wirex.HeaderContentDisposition))
+	assert.NotEmpty(t, recorder.Header().Get(wirex.HeaderETag))
+
+	recorder = serve(fileRequest(), FS(reportFS, "missing.txt"))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestFileRange(t *testing.T) {
+	recorder := serve(fileRequest("Range", "bytes=2-5"), FS(reportFS, "report.txt"))
+	assert.Equal(t, http.StatusPartialContent, recorder.Code)
+	assert.Equal(t, "bytes 2-5/10", recorder.Header().Get(wirex.HeaderContentRange))
+	assert.Equal(t, "2345", recorder.Body.String())
+
+	recorder = serve(fileRequest("Range", "bytes=0-1,8-9"), FS(reportFS, "report.txt"))
+	assert.Equal(t, http.StatusPartialContent, recorder.Code)
+
+	mediaType, params, err := mime.ParseMediaType(recorder.Header().Get(wirex.HeaderContentType))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+
+	var parts []string
+	reader := multipart.NewReader(recorder.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		data, _ := io.ReadAll(part)
+		parts = append(parts, part.Header.Get(wirex.HeaderContentRange)+" "+string(data))
+	}
+	assert.Equal(t, []string{"bytes 0-1/10 01", "bytes 8-9/10 89"}, parts)
+
+	recorder = serve(fileRequest("Range", "bytes=20-30"), FS(reportFS, "report.txt"))
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, recorder.Code)
+	assert.Equal(t, "bytes */10", recorder.Header().Get(wirex.HeaderContentRange))
+}
+
+func TestFileConditional(t *testing.T) {
+	etag := serve(fileRequest(), FS(reportFS, "report.txt")).Header().Get(wirex.HeaderETag)
+
+	recorder := serve(fileRequest(wirex.HeaderIfNoneMatch, etag), FS(reportFS, "report.txt"))
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = serve(fileRequest(wirex.HeaderIfModifiedSince, reportModTime.Format(http.TimeFormat)), FS(reportFS, "report.txt"))
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = serve(fileRequest(wirex.HeaderIfModifiedSince, reportModTime.Add(-time.Hour).Format(http.TimeFormat)), FS(reportFS, "report.txt"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "0123456789", recorder.Body.String())
+}
+
+type closingReader struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closingReader) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestAttachment(t *testing.T) {
+	content := &closingReader{Reader: strings.NewReader("id,email\n")}
+
+	recorder := serve(fileRequest(), Attachment("Übersicht 2024.csv", content, reportModTime))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "id,email\n", recorder.Body.String())
+	assert.True(t, content.closed)
+
+	// Non-ASCII names are sent with the RFC 2231 encoding.
+	disposition := recorder.Header().Get(wirex.HeaderContentDisposition)
+	assert.Equal(t, `attachment; filename*=utf-8''%C3%9Cbersicht%202024.csv`, disposition)
+
+	_, params, err := mime.ParseMediaType(disposition)
+	assert.NoError(t, err)
+	assert.Equal(t, "Übersicht 2024.csv", params["filename"])
+
+	recorder = serve(fileRequest(), Attachment(`say "hi".txt`, strings.NewReader("hi"), time.Time{}))
+	assert.Equal(t, `attachment; filename="say \"hi\".txt"`, recorder.Header().Get(wirex.HeaderContentDisposition))
+	assert.Empty(t, recorder.Header().Get(wirex.HeaderETag))
+}
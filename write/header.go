@@ -0,0 +1,164 @@
+package write
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bridgex-eu/wirex"
+)
+
+// HeaderFunc adapts an ordinary function to the wirex.HeaderWriter interface.
+type HeaderFunc func(w wirex.ResponseHeaderWriter, r *http.Request) wirex.HTTPError
+
+func (f HeaderFunc) WriteHeader(w wirex.ResponseHeaderWriter, r *http.Request) wirex.HTTPError {
+	return f(w, r)
+}
+
+// Header sets the header to the value, replacing any existing values.
+//
+// Usage Example:
+//
+//	return write.Json(http.StatusOK, &users, write.Header("X-Total-Count", strconv.Itoa(total)))
+func Header(key, value string) wirex.HeaderWriter {
+	return HeaderFunc(func(w wirex.ResponseHeaderWriter, r *http.Request) wirex.HTTPError {
+		w.Header().Set(key, value)
+		return nil
+	})
+}
+
+// SetCookie adds a Set-Cookie header for the cookie. An invalid cookie is answered with
+// 500 (Internal Server Error).
+func SetCookie(cookie *http.Cookie) wirex.HeaderWriter {
+	return HeaderFunc(func(w wirex.ResponseHeaderWriter, r *http.Request) wirex.HTTPError {
+		if err := cookie.Valid(); err != nil {
+			return wirex.Error(http.StatusInternalServerError, err)
+		}
+
+		w.Header().Add(wirex.HeaderSetCookie, cookie.String())
+		return nil
+	})
+}
+
+// DeleteCookie tells the client to delete the named cookie set for the root path.
+func DeleteCookie(name string) wirex.HeaderWriter {
+	return SetCookie(&http.Cookie{Name: name, Path: "/", MaxAge: -1, Expires: time.Unix(0, 0)})
+}
+
+// CacheControl sets the Cache-Control header to the directives.
+//
+// Usage Example:
+//
+//	write.CacheControl("public", "max-age=3600", "stale-while-revalidate=60")
+func CacheControl(directives ...string) wirex.HeaderWriter {
+	return Header(wirex.HeaderCacheControl, strings.Join(directives, ", "))
+}
+
+// Vary adds the fields to the Vary header, skipping those already listed.
+func Vary(fields ...string) wirex.HeaderWriter {
+	return HeaderFunc(func(w wirex.ResponseHeaderWriter, r *http.Request) wirex.HTTPError {
+		header := w.Header()
+		for _, field := range fields {
+			if !headerHasValue(header, wirex.HeaderVary, field) {
+				header.Add(wirex.HeaderVary, field)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ETag sets the entity tag of the response, quoting it if needed. A GET or HEAD request whose
+// If-None-Match matches the tag is answered with 304 (Not Modified) instead of the response. The header
+// writers listed after it don't run for a 304, so put it last.
+//
+// Usage Example:
+//
+//	return write.Json(http.StatusOK, &article, write.ETag(article.Revision))
+func ETag(tag string) wirex.HeaderWriter {
+	if !strings.HasPrefix(tag, `"`) && !strings.HasPrefix(tag, `W/"`) {
+		tag = `"` + tag + `"`
+	}
+
+	return HeaderFunc(func(w wirex.ResponseHeaderWriter, r *http.Request) wirex.HTTPError {
+		w.Header().Set(wirex.HeaderETag, tag)
+
+		if isSafe(r) && etagMatches(r.Header.Get(wirex.HeaderIfNoneMatch), tag) {
+			return errNotModified
+		}
+
+		return nil
+	})
+}
+
+// LastModified sets the modification time of the response. A GET or HEAD request without If-None-Match
+// whose If-Modified-Since isn't earlier is answered with 304 (Not Modified) instead of the response.
+func LastModified(t time.Time) wirex.HeaderWriter {
+	t = t.UTC().Truncate(time.Second)
+
+	return HeaderFunc(func(w wirex.ResponseHeaderWriter, r *http.Request) wirex.HTTPError {
+		if t.IsZero() {
+			return nil
+		}
+
+		w.Header().Set(wirex.HeaderLastModified, t.Format(http.TimeFormat))
+
+		if !isSafe(r) || r.Header.Get(wirex.HeaderIfNoneMatch) != "" {
+			return nil
+		}
+
+		since, err := http.ParseTime(r.Header.Get(wirex.HeaderIfModifiedSince))
+		if err == nil && !t.After(since) {
+			return errNotModified
+		}
+
+		return nil
+	})
+}
+
+func isSafe(r *http.Request) bool {
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+// etagMatches compares the tags of an If-None-Match header with tag, using the weak comparison.
+func etagMatches(header, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func headerHasValue(header http.Header, key, value string) bool {
+	for _, v := range header.Values(key) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// notModified answers a conditional request whose representation the client already has.
+type notModified struct{}
+
+var errNotModified wirex.HTTPError = notModified{}
+
+func (notModified) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	// Headers describing the omitted body don't apply to a 304 response.
+	w.Header().Del(wirex.HeaderContentType)
+	w.Header().Del(wirex.HeaderContentLength)
+
+	w.WriteHeader(http.StatusNotModified)
+}
+
+func (notModified) Error() string {
+	return "not modified"
+}
@@ -0,0 +1,111 @@
+package write
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bridgex-eu/wirex"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeader(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/users", nil),
+		String(http.StatusOK, "users", Header("X-Total-Count", "42"), CacheControl("public", "max-age=60")))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "users", recorder.Body.String())
+	assert.Equal(t, "42", recorder.Header().Get("X-Total-Count"))
+	assert.Equal(t, "public, max-age=60", recorder.Header().Get(wirex.HeaderCacheControl))
+}
+
+func TestSetCookie(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/login", nil),
+		String(http.StatusOK, "ok", SetCookie(&http.Cookie{Name: "session", Value: "abc", HttpOnly: true}), DeleteCookie("legacy")))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, []string{"session=abc; HttpOnly", "legacy=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0"},
+		recorder.Header().Values(wirex.HeaderSetCookie))
+
+	recorder = serve(httptest.NewRequest(http.MethodGet, "/login", nil),
+		String(http.StatusOK, "ok", SetCookie(&http.Cookie{Name: "bad name", Value: "abc"})))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Empty(t, recorder.Header().Values(wirex.HeaderSetCookie))
+}
+
+func TestVary(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/users", nil),
+		String(http.StatusOK, "users", Vary("Accept", "Accept-Language"), Vary("accept", "Cookie")))
+
+	assert.Equal(t, []string{"Accept", "Accept-Language", "Cookie"}, recorder.Header().Values(wirex.HeaderVary))
+}
+
+func TestETag(t *testing.T) {
+	get := func(method, ifNoneMatch string, tag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/articles/1", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(wirex.HeaderIfNoneMatch, ifNoneMatch)
+		}
+
+		return serve(req, String(http.StatusOK, "article", ETag(tag)))
+	}
+
+	recorder := get(http.MethodGet, "", "v2")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `"v2"`, recorder.Header().Get(wirex.HeaderETag))
+
+	for _, ifNoneMatch := range []string{`"v2"`, `"v1", W/"v2"`, "*"} {
+		recorder = get(http.MethodGet, ifNoneMatch, "v2")
+		assert.Equal(t, http.StatusNotModified, recorder.Code, ifNoneMatch)
+		assert.Empty(t, recorder.Body.String())
+		assert.Empty(t, recorder.Header().Get(wirex.HeaderContentType))
+		assert.Equal(t, `"v2"`, recorder.Header().Get(wirex.HeaderETag))
+	}
+
+	recorder = get(http.MethodGet, `"v1"`, "v2")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "article", recorder.Body.String())
+
+	recorder = get(http.MethodGet, `"v2"`, `W/"v2"`)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	// Conditional requests only apply to safe methods.
+	recorder = get(http.MethodPut, `"v2"`, "v2")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestLastModified(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+
+	get := func(header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		return serve(req, String(http.StatusOK, "article", LastModified(modified)))
+	}
+
+	recorder := get()
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "Fri, 01 Mar 2024 12:00:00 GMT", recorder.Header().Get(wirex.HeaderLastModified))
+
+	recorder = get(wirex.HeaderIfModifiedSince, "Fri, 01 Mar 2024 12:00:00 GMT")
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = get(wirex.HeaderIfModifiedSince, "Fri, 01 Mar 2024 11:59:59 GMT")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = get(wirex.HeaderIfModifiedSince, "not a date")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// If-None-Match takes precedence over If-Modified-Since.
+	recorder = get(wirex.HeaderIfModifiedSince, "Fri, 01 Mar 2024 12:00:00 GMT", wirex.HeaderIfNoneMatch, `"v1"`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = serve(httptest.NewRequest(http.MethodGet, "/articles/1", nil), String(http.StatusOK, "article", LastModified(time.Time{})))
+	assert.Empty(t, recorder.Header().Get(wirex.HeaderLastModified))
+}
@@ -0,0 +1,32 @@
+package write
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/bridgex-eu/wirex"
+)
+
+// HTMLData represents a page of the Engine's Templates rendered with data.
+type HTMLData struct {
+	Status int
+	Name   string
+	Data   any
+}
+
+var _ wirex.Writer = &HTMLData{}
+
+func (h *HTMLData) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	if err := wirex.WriteHTML(w, r, h.Status, h.Name, h.Data); err != nil {
+		slog.Error("cannot write html to response", "error", err)
+	}
+}
+
+// HTML renders the named page of the Engine's Templates with data. See wirex.Templates.
+//
+// Usage Example:
+//
+//	return write.HTML(http.StatusOK, "users/show", user)
+func HTML(status int, name string, data any, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&HTMLData{Status: status, Name: name, Data: data}, other...)
+}
@@ -0,0 +1,144 @@
+package write
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is the number of events queued for a subscriber before new events are dropped.
+const subscriberBuffer = 64
+
+// Hub fans out events published on topics to the subscribers of these topics, typically the
+// Server-Sent Events streams of many clients. It's safe for concurrent use.
+//
+// Publishing never blocks: a subscriber whose queue is full misses the events until it catches up.
+// The last events of each topic are kept, so reconnecting clients are sent the events they missed
+// after their Last-Event-ID. Topics are created as needed, and removed once they have neither
+// subscribers nor events kept, so topics per entity, like "order:42", are fine.
+//
+// Usage Example:
+//
+//	hub := write.NewHub(100)
+//
+//	engine.Route("/events").Get(func(r *http.Request) wirex.Writer {
+//		return write.SSE(hub.Stream("orders"))
+//	})
+//
+//	hub.Publish("orders", write.Event{ID: order.ID, Event: "created", Data: data})
+type Hub struct {
+	mu      sync.RWMutex
+	history int
+	topics  map[string]*topic
+}
+
+type topic struct {
+	subscribers map[chan Event]struct{}
+	events      []Event // The last events, oldest first.
+}
+
+// NewHub returns a Hub keeping the last history events of each topic for replay.
+func NewHub(history int) *Hub {
+	return &Hub{history: history, topics: map[string]*topic{}}
+}
+
+// Publish sends the event to the subscribers of the topic.
+func (h *Hub) Publish(name string, e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// A topic nobody listens to is only needed to keep its history.
+	t, ok := h.topics[name]
+	if !ok {
+		if h.history == 0 || e.ID == "" {
+			return
+		}
+		t = h.topic(name)
+	}
+
+	if h.history > 0 && e.ID != "" {
+		if len(t.events) == h.history {
+			t.events = t.events[1:]
+		}
+		t.events = append(t.events, e)
+	}
+
+	for ch := range t.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events published on the topics, starting with the events
+// kept after lastEventID, if it's found. The channel is closed once ctx is done.
+func (h *Hub) Subscribe(ctx context.Context, lastEventID string, topics ...string) <-chan Event {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	for _, name := range topics {
+		t := h.topic(name)
+		t.subscribers[ch] = struct{}{}
+
+		if lastEventID != "" {
+			for i, e := range t.events {
+				if e.ID != lastEventID {
+					continue
+				}
+
+				for _, missed := range t.events[i+1:] {
+					select {
+					case ch <- missed:
+					default:
+					}
+				}
+				break
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		for _, name := range topics {
+			t := h.topics[name]
+			delete(t.subscribers, ch)
+
+			// Topics are removed once they're of no use, so per-entity topics don't pile up.
+			if len(t.subscribers) == 0 && len(t.events) == 0 {
+				delete(h.topics, name)
+			}
+		}
+		close(ch)
+	}()
+
+	return ch
+}
+
+// Stream returns a stream function for SSE that forwards the events of the topics to the client
+// until it disconnects.
+func (h *Hub) Stream(topics ...string) func(*EventStream) error {
+	return func(stream *EventStream) error {
+		for e := range h.Subscribe(stream.Context(), stream.LastEventID(), topics...) {
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func (h *Hub) topic(name string) *topic {
+	t, ok := h.topics[name]
+	if !ok {
+		t = &topic{subscribers: map[chan Event]struct{}{}}
+		h.topics[name] = t
+	}
+
+	return t
+}
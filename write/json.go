@@ -1,7 +1,7 @@
 package write
 
 import (
-	"encoding/json"
+	"log/slog"
 	"net/http"
 
 	"github.com/bridgex-eu/wirex"
@@ -15,12 +15,8 @@ type JsonData[T any] struct {
 var _ wirex.Writer = &JsonData[int]{}
 
 func (j *JsonData[T]) WriteResponse(w http.ResponseWriter, r *http.Request) {
-	SetContentType(w, wirex.MIMEApplicationJSON)
-
-	w.WriteHeader(j.Status)
-
-	if err := json.NewEncoder(w).Encode(j.Data); err != nil {
-		String(http.StatusInternalServerError, err.Error()).WriteResponse(w, r)
+	if err := wirex.WriteJSON(w, r, j.Status, wirex.MIMEApplicationJSON, j.Data); err != nil {
+		slog.Error("cannot write json to response", "error", err)
 	}
 }
 
@@ -0,0 +1,16 @@
+package write
+
+import (
+	"github.com/bridgex-eu/wirex"
+)
+
+// Problem returns an RFC 9457 problem details response with the given status and detail.
+// Extension members are added with wirex.Problem.With.
+//
+// Usage Example:
+//
+//	return write.Problem(http.StatusConflict, "The email is already taken.").
+//		With("field", "email")
+func Problem(status int, detail string) *wirex.Problem {
+	return wirex.NewProblem(status, detail, nil)
+}
@@ -8,18 +8,100 @@ import (
 
 // RedirectData represents the URL needed for an HTTP redirect.
 type RedirectData struct {
-	Url string
+	Url    string
+	Status int    // The redirect status code, 302(Found) when zero.
+	Route  string // The name of the route to redirect to, instead of Url.
+	Params []any  // The params of the route wildcards.
+
+	// Safe checks the target with wirex.CheckRedirect and answers an unsafe one with 400 (Bad Request),
+	// for targets coming from the client, like a "next" query parameter. Absolute URLs to other hosts
+	// must then be listed in the RedirectHosts of the Engine.
+	Safe bool
+
+	// Back redirects to the Referer of the request when it passes wirex.CheckRedirect, and to the
+	// target otherwise.
+	Back bool
 }
 
 var _ wirex.Writer = &RedirectData{}
 
 // WriteResponse writes the HTTP redirect response.
 func (rd *RedirectData) WriteResponse(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Location", rd.Url)
-	w.WriteHeader(http.StatusFound) // or use 302 directly
+	target := rd.Url
+	if rd.Route != "" {
+		var err error
+		if target, err = wirex.RouteURL(r, rd.Route, rd.Params...); err != nil {
+			wirex.Error(http.StatusInternalServerError, err).WriteResponse(w, r)
+			return
+		}
+	}
+
+	if referer := r.Referer(); rd.Back && referer != "" && wirex.CheckRedirect(r, referer) == nil {
+		target = referer
+	}
+
+	if rd.Safe {
+		if err := wirex.CheckRedirect(r, target); err != nil {
+			wirex.Error(http.StatusBadRequest, err).WriteResponse(w, r)
+			return
+		}
+	}
+
+	status := rd.Status
+	if status == 0 {
+		status = http.StatusFound
+	}
+
+	w.Header().Set(wirex.HeaderLocation, target)
+	w.WriteHeader(status)
 }
 
 // Redirect with the provided URL and 302(Found) status code.
 func Redirect(url string, other ...wirex.HeaderWriter) wirex.Writer {
 	return WithHeader(&RedirectData{Url: url}, other...)
 }
+
+// RedirectWith redirects to the URL with the given status code, like 301(Moved Permanently),
+// 303(See Other), 307(Temporary Redirect) or 308(Permanent Redirect).
+//
+// Usage Example:
+//
+//	return write.RedirectWith(http.StatusSeeOther, "/orders/"+order.ID)
+func RedirectWith(status int, url string, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&RedirectData{Url: url, Status: status}, other...)
+}
+
+// RedirectSafe redirects to a URL coming from the client with 302(Found), once it passes
+// wirex.CheckRedirect. An unsafe URL is answered with 400 (Bad Request), to prevent open redirects.
+//
+// Usage Example:
+//
+//	return write.RedirectSafe(r.URL.Query().Get("next"))
+func RedirectSafe(url string, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&RedirectData{Url: url, Safe: true}, other...)
+}
+
+// RedirectBack redirects to the page the request came from, according to its Referer, or to the
+// fallback URL if there's none or it's not safe, with 303(See Other).
+func RedirectBack(fallback string, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&RedirectData{Url: fallback, Status: http.StatusSeeOther, Back: true}, other...)
+}
+
+// RedirectRoute redirects to the named route, with its wildcards filled with params, and 302(Found)
+// status code. See wirex.Engine.URL. Headers are added by wrapping it with WithHeader.
+//
+// Usage Example:
+//
+//	return write.RedirectRoute("user", user.ID)
+func RedirectRoute(name string, params ...any) wirex.Writer {
+	return &RedirectData{Route: name, Params: params}
+}
+
+// RedirectRouteWith redirects to the named route with the given status code, see RedirectRoute.
+//
+// Usage Example:
+//
+//	return write.RedirectRouteWith(http.StatusSeeOther, "order", order.ID)
+func RedirectRouteWith(status int, name string, params ...any) wirex.Writer {
+	return &RedirectData{Route: name, Params: params, Status: status}
+}
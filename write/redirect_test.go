@@ -0,0 +1,92 @@
+package write
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bridgex-eu/wirex"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirect(t *testing.T) {
+	// Redirects to the targets chosen by the server aren't checked.
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/login", nil), Redirect("https://accounts.example.net/login", Header("X-Reason", "sso")))
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "https://accounts.example.net/login", recorder.Header().Get(wirex.HeaderLocation))
+	assert.Equal(t, "sso", recorder.Header().Get("X-Reason"))
+
+	for _, status := range []int{http.StatusMovedPermanently, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect} {
+		recorder = serve(httptest.NewRequest(http.MethodPost, "/orders", nil), RedirectWith(status, "/orders/7"))
+		assert.Equal(t, status, recorder.Code)
+		assert.Equal(t, "/orders/7", recorder.Header().Get(wirex.HeaderLocation))
+	}
+}
+
+func TestRedirectSafe(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/login", nil), RedirectSafe("/dashboard"))
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "/dashboard", recorder.Header().Get(wirex.HeaderLocation))
+
+	recorder = serve(httptest.NewRequest(http.MethodGet, "/login", nil), RedirectSafe("//evil.example/"))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(wirex.HeaderLocation))
+}
+
+func TestRedirectBack(t *testing.T) {
+	back := func(referer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/cart", nil)
+		if referer != "" {
+			req.Header.Set("Referer", referer)
+		}
+
+		return serve(req, RedirectBack("/shop"))
+	}
+
+	for referer, location := range map[string]string{
+		"http://example.com/shop/shoes?page=2": "http://example.com/shop/shoes?page=2",
+		"/shop/hats":                           "/shop/hats",
+		"https://evil.example/phish":           "/shop",
+		"":                                     "/shop",
+	} {
+		recorder := back(referer)
+		assert.Equal(t, http.StatusSeeOther, recorder.Code, referer)
+		assert.Equal(t, location, recorder.Header().Get(wirex.HeaderLocation), referer)
+	}
+}
+
+func TestRedirectRoute(t *testing.T) {
+	engine := wirex.New()
+	engine.Route("/users/{id:int}/files/{path...}").Get(func(r *http.Request) wirex.Writer { return nil }).Name("file")
+	engine.Route("/to-file").Get(func(r *http.Request) wirex.Writer {
+		return WithHeader(RedirectRoute("file", 42, "docs/a b.txt"), Header("X-Reason", "moved"))
+	})
+	engine.Route("/to-file-see-other").Post(func(r *http.Request) wirex.Writer {
+		return RedirectRouteWith(http.StatusSeeOther, "file", 7, "report.pdf")
+	})
+	engine.Route("/to-missing").Get(func(r *http.Request) wirex.Writer {
+		return RedirectRoute("missing")
+	})
+	engine.Route("/to-invalid").Get(func(r *http.Request) wirex.Writer {
+		return RedirectRoute("file", "bob", "a.txt")
+	})
+
+	handler := engine.Handler()
+	request := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+
+	recorder := request(http.MethodGet, "/to-file")
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "/users/42/files/docs/a%20b.txt", recorder.Header().Get(wirex.HeaderLocation))
+	assert.Equal(t, "moved", recorder.Header().Get("X-Reason"))
+
+	recorder = request(http.MethodPost, "/to-file-see-other")
+	assert.Equal(t, http.StatusSeeOther, recorder.Code)
+	assert.Equal(t, "/users/7/files/report.pdf", recorder.Header().Get(wirex.HeaderLocation))
+
+	assert.Equal(t, http.StatusInternalServerError, request(http.MethodGet, "/to-missing").Code)
+	assert.Equal(t, http.StatusInternalServerError, request(http.MethodGet, "/to-invalid").Code)
+}
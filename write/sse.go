@@ -0,0 +1,183 @@
+package write
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bridgex-eu/wirex"
+)
+
+// DefaultHeartbeat is the interval of the comments SSE sends to keep idle connections open.
+const DefaultHeartbeat = 15 * time.Second
+
+// Event is a Server-Sent Event.
+type Event struct {
+	ID    string        // Sent back by the client in the Last-Event-ID header when it reconnects.
+	Event string        // The event type, "message" when empty.
+	Data  string        // The event payload. Multi-line data is sent as multiple data fields.
+	Retry time.Duration // The reconnection delay the client should use, if not zero.
+}
+
+// EventStream sends Server-Sent Events to one client. It's safe for concurrent use.
+type EventStream struct {
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	rc          *http.ResponseController
+	ctx         context.Context
+	lastEventID string
+}
+
+// Context returns the request context, which is cancelled when the client disconnects.
+func (s *EventStream) Context() context.Context {
+	return s.ctx
+}
+
+// LastEventID returns the ID of the last event the client received before reconnecting, from the
+// Last-Event-ID header, or the empty string on the first connection.
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Send writes the event and flushes it to the client. It returns an error once the client is gone.
+func (s *EventStream) Send(e Event) error {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + singleLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + singleLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(e.Data, "\r\n", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+// SendJSON sends an event of the given type with v encoded as JSON.
+func (s *EventStream) SendJSON(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.Send(Event{Event: event, Data: string(data)})
+}
+
+func (s *EventStream) write(chunk string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, err := s.w.Write([]byte(chunk)); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// SSEData represents a stream of Server-Sent Events.
+type SSEData struct {
+	Stream    func(*EventStream) error
+	Heartbeat time.Duration // The interval of keep-alive comments, none if negative.
+}
+
+var _ wirex.Writer = &SSEData{}
+
+// WriteResponse opens the event stream and runs Stream until it returns or the client disconnects.
+func (s *SSEData) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set(wirex.HeaderContentType, wirex.MIMETextEventStream)
+	header.Set(wirex.HeaderCacheControl, "no-cache")
+	header.Set("X-Accel-Buffering", "no") // Disables response buffering in nginx.
+
+	stream := &EventStream{
+		w:           w,
+		rc:          http.NewResponseController(w),
+		ctx:         r.Context(),
+		lastEventID: r.Header.Get(wirex.HeaderLastEventID),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := stream.rc.Flush(); err != nil {
+		slog.Error("cannot stream server-sent events", "error", err)
+		return
+	}
+
+	if s.Heartbeat >= 0 {
+		heartbeat := s.Heartbeat
+		if heartbeat == 0 {
+			heartbeat = DefaultHeartbeat
+		}
+
+		// The heartbeat goroutine is waited for, it must not write to the response once it's over.
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		defer wg.Wait()
+		defer close(done)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-stream.ctx.Done():
+					return
+				case <-ticker.C:
+					if stream.write(": heartbeat\n\n") != nil {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	if err := s.Stream(stream); err != nil && stream.ctx.Err() == nil {
+		slog.Error("server-sent events stream failed", "error", err)
+	}
+}
+
+// SSE streams Server-Sent Events written by the stream function, which should return when the
+// request context is cancelled or Send fails. Idle connections are kept open with a comment sent
+// every DefaultHeartbeat.
+//
+// Usage Example:
+//
+//	return write.SSE(func(stream *write.EventStream) error {
+//		for {
+//			select {
+//			case <-stream.Context().Done():
+//				return nil
+//			case order := <-orders:
+//				if err := stream.SendJSON("order", order); err != nil {
+//					return err
+//				}
+//			}
+//		}
+//	})
+func SSE(stream func(*EventStream) error, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&SSEData{Stream: stream}, other...)
+}
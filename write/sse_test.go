@@ -0,0 +1,199 @@
+package write
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bridgex-eu/wirex"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSESend(t *testing.T) {
+	writer := &SSEData{Heartbeat: -1, Stream: func(stream *EventStream) error {
+		if err := stream.Send(Event{ID: "7", Event: "update", Data: "first\nsecond\r\nthird", Retry: 3 * time.Second}); err != nil {
+			return err
+		}
+
+		// Line breaks would end the field early, they're removed from single-line fields.
+		if err := stream.Send(Event{ID: "8\n", Event: "up\r\ndate", Data: ""}); err != nil {
+			return err
+		}
+
+		return stream.SendJSON("user", map[string]string{"name": "bob"})
+	}}
+
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/events", nil), writer)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, wirex.MIMETextEventStream, recorder.Header().Get(wirex.HeaderContentType))
+	assert.Equal(t, "no-cache", recorder.Header().Get(wirex.HeaderCacheControl))
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, "id: 7\nevent: update\nretry: 3000\ndata: first\ndata: second\ndata: third\n\n"+
+		"id: 8\nevent: update\ndata: \n\n"+
+		"event: user\ndata: {\"name\":\"bob\"}\n\n", recorder.Body.String())
+}
+
+func TestSSELastEventID(t *testing.T) {
+	var first, reconnect string
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	serve(req, &SSEData{Heartbeat: -1, Stream: func(stream *EventStream) error {
+		first = stream.LastEventID()
+		return nil
+	}})
+
+	req = httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set(wirex.HeaderLastEventID, "41")
+	serve(req, &SSEData{Heartbeat: -1, Stream: func(stream *EventStream) error {
+		reconnect = stream.LastEventID()
+		return nil
+	}})
+
+	assert.Empty(t, first)
+	assert.Equal(t, "41", reconnect)
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/events", nil), &SSEData{
+		Heartbeat: time.Millisecond,
+		Stream: func(stream *EventStream) error {
+			time.Sleep(10 * time.Millisecond)
+			return stream.Send(Event{Data: "done"})
+		},
+	})
+
+	body := recorder.Body.String()
+	assert.Contains(t, body, ": heartbeat\n\n")
+	assert.Contains(t, body, "data: done\n\n")
+
+	// A heartbeat may be due when the stream returns, it must be over before the response is read.
+	for range 100 {
+		recorder := serve(httptest.NewRequest(http.MethodGet, "/events", nil), &SSEData{
+			Heartbeat: time.Microsecond,
+			Stream:    func(stream *EventStream) error { return nil },
+		})
+		assert.NotContains(t, recorder.Body.String(), "data:")
+	}
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	orders := hub.Subscribe(ctx, "", "orders")
+	all := hub.Subscribe(ctx, "", "orders", "users")
+
+	hub.Publish("orders", Event{ID: "1", Data: "order"})
+	hub.Publish("users", Event{ID: "2", Data: "user"})
+
+	assert.Equal(t, "order", (<-orders).Data)
+	assert.Equal(t, "order", (<-all).Data)
+	assert.Equal(t, "user", (<-all).Data)
+	assert.Empty(t, orders)
+
+	cancel()
+
+	// The channels are closed and the subscribers removed once the context is done.
+	_, open := <-orders
+	assert.False(t, open)
+	_, open = <-all
+	assert.False(t, open)
+
+	// The topics are removed along with their last subscriber.
+	hub.mu.RLock()
+	assert.Len(t, hub.topics, 0)
+	hub.mu.RUnlock()
+
+	// Without history, publishing to a topic nobody listens to keeps nothing.
+	hub.Publish("orders", Event{ID: "3", Data: "order"})
+
+	hub.mu.RLock()
+	assert.Len(t, hub.topics, 0)
+	hub.mu.RUnlock()
+}
+
+func TestHubTopics(t *testing.T) {
+	hub := NewHub(2)
+
+	// With history, topics are kept for their events, which need an ID to be replayed.
+	hub.Publish("user:1", Event{ID: "1"})
+	hub.Publish("user:2", Event{Data: "no id"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := hub.Subscribe(ctx, "", "user:1", "user:3")
+	cancel()
+	for range ch {
+	}
+
+	hub.mu.RLock()
+	assert.Len(t, hub.topics, 1)
+	assert.Contains(t, hub.topics, "user:1")
+	hub.mu.RUnlock()
+}
+
+func TestHubReplay(t *testing.T) {
+	hub := NewHub(3)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		hub.Publish("orders", Event{ID: id})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Only the last 3 events are kept, so "1" isn't found and nothing is replayed.
+	assert.Empty(t, hub.Subscribe(ctx, "1", "orders"))
+
+	missed := hub.Subscribe(ctx, "2", "orders")
+	assert.Equal(t, "3", (<-missed).ID)
+	assert.Equal(t, "4", (<-missed).ID)
+	assert.Empty(t, missed)
+}
+
+func TestHubStream(t *testing.T) {
+	hub := NewHub(10)
+	hub.Publish("orders", Event{ID: "1", Data: "old"})
+	hub.Publish("orders", Event{ID: "2", Data: "missed"})
+
+	engine := wirex.New()
+	engine.Route("/events").Get(func(r *http.Request) wirex.Writer {
+		return &SSEData{Heartbeat: -1, Stream: hub.Stream("orders")}
+	})
+
+	server := httptest.NewServer(engine.Handler())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	req.Header.Set(wirex.HeaderLastEventID, "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Could not make GET request to /events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := bufio.NewReader(resp.Body)
+	assert.Equal(t, "id: 2\ndata: missed\n\n", readEvent(t, lines))
+
+	hub.Publish("orders", Event{ID: "3", Data: "live"})
+	assert.Equal(t, "id: 3\ndata: live\n\n", readEvent(t, lines))
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) string {
+	var event strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Could not read event: %v", err)
+		}
+
+		event.WriteString(line)
+		if line == "\n" {
+			return event.String()
+		}
+	}
+}
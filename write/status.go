@@ -0,0 +1,54 @@
+package write
+
+import (
+	"net/http"
+
+	"github.com/bridgex-eu/wirex"
+)
+
+// StatusData represents a response without a body, optionally pointing to another resource.
+type StatusData struct {
+	Status   int
+	Location string
+}
+
+var _ wirex.Writer = &StatusData{}
+
+// WriteResponse writes the status code and the Location header, if set.
+func (s *StatusData) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	if s.Location != "" {
+		w.Header().Set(wirex.HeaderLocation, s.Location)
+	}
+
+	w.WriteHeader(s.Status)
+}
+
+// NoContent responds with 204(No Content). Returning a nil Writer from a handler does the same.
+func NoContent(other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&StatusData{Status: http.StatusNoContent}, other...)
+}
+
+// Created responds with 201(Created), the location of the new resource and its JSON representation.
+// The body is omitted if it's nil.
+func Created[T any](location string, body *T, other ...wirex.HeaderWriter) wirex.Writer {
+	if body == nil {
+		return WithHeader(&StatusData{Status: http.StatusCreated, Location: location}, other...)
+	}
+
+	return Json(http.StatusCreated, body, append([]wirex.HeaderWriter{locationHeader(location)}, other...)...)
+}
+
+// Accepted responds with 202(Accepted) and the location of a resource reporting the processing status.
+func Accepted(statusURL string, other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&StatusData{Status: http.StatusAccepted, Location: statusURL}, other...)
+}
+
+type locationHeader string
+
+func (l locationHeader) WriteHeader(w wirex.ResponseHeaderWriter, r *http.Request) wirex.HTTPError {
+	if l != "" {
+		w.Header().Set(wirex.HeaderLocation, string(l))
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package write
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bridgex-eu/wirex"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoContent(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodDelete, "/users/1", nil), NoContent(Header("X-Deleted", "1")))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("X-Deleted"))
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestCreated(t *testing.T) {
+	user := struct {
+		Name string `json:"name"`
+	}{"bob"}
+
+	recorder := serve(httptest.NewRequest(http.MethodPost, "/users", nil), Created("/users/1", &user))
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "/users/1", recorder.Header().Get(wirex.HeaderLocation))
+	assert.Equal(t, wirex.MIMEApplicationJSON, recorder.Header().Get(wirex.HeaderContentType))
+	assert.JSONEq(t, `{"name":"bob"}`, recorder.Body.String())
+
+	recorder = serve(httptest.NewRequest(http.MethodPost, "/users", nil), Created[struct{}]("/users/2", nil))
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "/users/2", recorder.Header().Get(wirex.HeaderLocation))
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestAccepted(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodPost, "/exports", nil), Accepted("/exports/7/status"))
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, "/exports/7/status", recorder.Header().Get(wirex.HeaderLocation))
+	assert.Empty(t, recorder.Body.String())
+}
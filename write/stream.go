@@ -0,0 +1,384 @@
+package write
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bridgex-eu/wirex"
+)
+
+// DefaultFlushInterval is how often streamed responses are flushed to the client, unless set otherwise.
+const DefaultFlushInterval = time.Second
+
+// Source produces the items of a streamed response by passing them to emit, and returns once it's done.
+//
+// Emit returns an error once the client is gone or the item can't be written, after which the source
+// should return that error. The context is cancelled when the client disconnects.
+type Source[T any] func(ctx context.Context, emit func(T) error) error
+
+// FromSeq returns a Source for an iterator, like an iter.Seq.
+func FromSeq[T any](seq func(yield func(T) bool)) Source[T] {
+	return func(ctx context.Context, emit func(T) error) error {
+		var err error
+		seq(func(v T) bool {
+			err = emit(v)
+			return err == nil
+		})
+
+		return err
+	}
+}
+
+// FromChan returns a Source for the values received from ch until it's closed.
+func FromChan[T any](ch <-chan T) Source[T] {
+	return func(ctx context.Context, emit func(T) error) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case v, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				if err := emit(v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// StreamFormat writes the items of a streamed response in a given format.
+type StreamFormat[T any] interface {
+	Begin(w io.Writer) error
+	Item(w io.Writer, i int, v T) error
+	End(w io.Writer) error
+	Fail(w io.Writer, message string) error // Ends a stream whose source failed.
+}
+
+// StreamData represents a response streamed from a Source, item by item, without holding all of it in
+// memory.
+//
+// The status and headers are sent with the first item, so a Source failing before it is answered with
+// its HTTPError, or with 500 (Internal Server Error). A failure afterwards is reported in the
+// X-Stream-Error trailer, and the format may end the body with an error record.
+type StreamData[T any] struct {
+	Status        int
+	ContentType   string
+	Source        Source[T]
+	Format        StreamFormat[T]
+	FlushInterval time.Duration // How often the items written so far are flushed, DefaultFlushInterval when zero.
+}
+
+var _ wirex.Writer = &StreamData[int]{}
+
+func (s *StreamData[T]) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bw := bufio.NewWriter(w)
+
+	interval := s.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+
+	// The items are flushed from a ticker, so they don't wait in the buffer while the source is idle.
+	var mu sync.Mutex
+	var flushErr error
+	started, count := false, 0
+
+	flusher := newIntervalFlusher(interval, func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !started || flushErr != nil || bw.Buffered() == 0 {
+			return
+		}
+
+		flushErr = flush(w, bw)
+	})
+	defer flusher.stop()
+
+	start := func() error {
+		started = true
+
+		SetContentType(w, s.ContentType)
+		w.Header().Set(wirex.HeaderTrailer, wirex.HeaderXStreamError)
+		w.WriteHeader(s.Status)
+
+		return s.Format.Begin(bw)
+	}
+
+	emit := func(v T) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if flushErr != nil {
+			return flushErr
+		}
+
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if err := s.Format.Item(bw, count, v); err != nil {
+			return err
+		}
+		count++
+
+		return nil
+	}
+
+	err := s.Source(ctx, emit)
+	flusher.stop()
+
+	if ctx.Err() != nil {
+		// The client is gone, there's no one to tell.
+		return
+	}
+
+	if err != nil && !started {
+		var httpErr wirex.HTTPError
+		if !errors.As(err, &httpErr) {
+			httpErr = wirex.Error(http.StatusInternalServerError, err)
+		}
+
+		httpErr.WriteResponse(w, r)
+		return
+	}
+
+	if !started {
+		if startErr := start(); startErr != nil {
+			slog.Error("cannot write stream", "error", startErr)
+			return
+		}
+	}
+
+	if err != nil {
+		slog.Error("stream failed", "error", err, "items", count)
+
+		message := streamErrorMessage(err)
+		w.Header().Set(wirex.HeaderXStreamError, message)
+		err = s.Format.Fail(bw, message)
+	} else {
+		err = s.Format.End(bw)
+	}
+
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		slog.Error("cannot write stream", "error", err)
+	}
+}
+
+// flush sends the buffered items to the client.
+func flush(w http.ResponseWriter, bw *bufio.Writer) error {
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	if err := http.NewResponseController(w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+// intervalFlusher calls a flush function at regular intervals until it's stopped.
+type intervalFlusher struct {
+	once sync.Once
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newIntervalFlusher(interval time.Duration, flush func()) *intervalFlusher {
+	f := &intervalFlusher{done: make(chan struct{})}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-f.done:
+				return
+			case <-ticker.C:
+				flush()
+			}
+		}
+	}()
+
+	return f
+}
+
+// stop ends the flushes and waits for the one in progress, if any.
+func (f *intervalFlusher) stop() {
+	f.once.Do(func() { close(f.done) })
+	f.wg.Wait()
+}
+
+// streamErrorMessage returns the message of the error meant for the client.
+func streamErrorMessage(err error) string {
+	var httpErr *wirex.DefaultHTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Message
+	}
+
+	var problem *wirex.Problem
+	if errors.As(err, &problem) && problem.Detail != "" {
+		return problem.Detail
+	}
+
+	return http.StatusText(http.StatusInternalServerError)
+}
+
+// ndjson writes each item as a JSON line, and ends a failed stream with an {"error": message} line.
+type ndjson[T any] struct{}
+
+func (ndjson[T]) Begin(w io.Writer) error { return nil }
+
+func (ndjson[T]) Item(w io.Writer, i int, v T) error { return json.NewEncoder(w).Encode(v) }
+
+func (ndjson[T]) End(w io.Writer) error { return nil }
+
+func (ndjson[T]) Fail(w io.Writer, message string) error {
+	return json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// jsonArray writes the items as the elements of a JSON array, which is left unterminated when the
+// stream fails, so the client can't mistake the partial array for the whole.
+type jsonArray[T any] struct{}
+
+func (jsonArray[T]) Begin(w io.Writer) error {
+	_, err := io.WriteString(w, "[")
+	return err
+}
+
+func (jsonArray[T]) Item(w io.Writer, i int, v T) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if i > 0 {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (jsonArray[T]) End(w io.Writer) error {
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+func (jsonArray[T]) Fail(w io.Writer, message string) error { return nil }
+
+// csvRows writes a header row followed by the items as CSV records. A failed stream is reported by
+// the trailer only.
+type csvRows struct {
+	header []string
+}
+
+func (c csvRows) Begin(w io.Writer) error {
+	if len(c.header) == 0 {
+		return nil
+	}
+
+	return c.Item(w, 0, c.header)
+}
+
+func (csvRows) Item(w io.Writer, i int, v []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(v); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (csvRows) End(w io.Writer) error { return nil }
+
+func (csvRows) Fail(w io.Writer, message string) error { return nil }
+
+// StreamNDJSON streams the items of the source as newline-delimited JSON. A failed stream ends with an
+// {"error": message} line.
+//
+// Usage Example:
+//
+//	return write.StreamNDJSON(func(ctx context.Context, emit func(Order) error) error {
+//		rows, err := db.QueryContext(ctx, "SELECT id, total FROM orders")
+//		if err != nil {
+//			return err
+//		}
+//		defer rows.Close()
+//
+//		for rows.Next() {
+//			var o Order
+//			if err := rows.Scan(&o.ID, &o.Total); err != nil {
+//				return err
+//			}
+//			if err := emit(o); err != nil {
+//				return err
+//			}
+//		}
+//		return rows.Err()
+//	})
+func StreamNDJSON[T any](src Source[T], other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&StreamData[T]{
+		Status:      http.StatusOK,
+		ContentType: wirex.MIMEApplicationNDJSON,
+		Source:      src,
+		Format:      ndjson[T]{},
+	}, other...)
+}
+
+// StreamJSONArray streams the items of the source as a JSON array. The array of a failed stream is
+// left unterminated.
+//
+// Usage Example:
+//
+//	return write.StreamJSONArray(write.FromChan(results))
+func StreamJSONArray[T any](src Source[T], other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&StreamData[T]{
+		Status:      http.StatusOK,
+		ContentType: wirex.MIMEApplicationJSON,
+		Source:      src,
+		Format:      jsonArray[T]{},
+	}, other...)
+}
+
+// StreamCSV streams the records of the source as CSV, after the header row if it's not empty.
+//
+// Usage Example:
+//
+//	return write.StreamCSV([]string{"id", "email"}, write.FromSeq(users.Rows(r.Context())))
+func StreamCSV(header []string, src Source[[]string], other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&StreamData[[]string]{
+		Status:      http.StatusOK,
+		ContentType: wirex.MIMETextCSVCharsetUTF8,
+		Source:      src,
+		Format:      csvRows{header: header},
+	}, other...)
+}
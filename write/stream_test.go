@@ -0,0 +1,132 @@
+package write
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bridgex-eu/wirex"
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	ID int `json:"id"`
+}
+
+func items(ids ...int) Source[item] {
+	return FromSeq(func(yield func(item) bool) {
+		for _, id := range ids {
+			if !yield(item{ID: id}) {
+				return
+			}
+		}
+	})
+}
+
+// failing returns a source emitting the items, then failing with err.
+func failing(err error, ids ...int) Source[item] {
+	return func(ctx context.Context, emit func(item) error) error {
+		if e := items(ids...)(ctx, emit); e != nil {
+			return e
+		}
+
+		return err
+	}
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/items", nil), StreamNDJSON(items(1, 2, 3)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, wirex.MIMEApplicationNDJSON, recorder.Header().Get(wirex.HeaderContentType))
+	assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n", recorder.Body.String())
+	assert.Empty(t, recorder.Result().Trailer.Get(wirex.HeaderXStreamError))
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/items", nil), StreamJSONArray(items(1, 2)))
+	assert.Equal(t, wirex.MIMEApplicationJSON, recorder.Header().Get(wirex.HeaderContentType))
+	assert.Equal(t, "[{\"id\":1},{\"id\":2}]\n", recorder.Body.String())
+
+	recorder = serve(httptest.NewRequest(http.MethodGet, "/items", nil), StreamJSONArray(items()))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "[]\n", recorder.Body.String())
+}
+
+func TestStreamCSV(t *testing.T) {
+	rows := FromSeq(func(yield func([]string) bool) {
+		_ = yield([]string{"1", "bob@example.com"}) && yield([]string{"2", "Doe, Jane"})
+	})
+
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/users.csv", nil), StreamCSV([]string{"id", "email"}, rows))
+
+	assert.Equal(t, wirex.MIMETextCSVCharsetUTF8, recorder.Header().Get(wirex.HeaderContentType))
+	assert.Equal(t, "id,email\n1,bob@example.com\n2,\"Doe, Jane\"\n", recorder.Body.String())
+}
+
+func TestStreamFailure(t *testing.T) {
+	// Once the stream has started, the failure is reported in the trailer and the body.
+	recorder := serve(httptest.NewRequest(http.MethodGet, "/items", nil), StreamNDJSON(failing(errors.New("connection reset"), 1)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "{\"id\":1}\n{\"error\":\"Internal Server Error\"}\n", recorder.Body.String())
+	assert.Equal(t, "Internal Server Error", recorder.Result().Trailer.Get(wirex.HeaderXStreamError))
+
+	recorder = serve(httptest.NewRequest(http.MethodGet, "/items", nil), StreamJSONArray(failing(wirex.Error(http.StatusConflict, errors.New("changed")), 1)))
+	assert.Equal(t, "[{\"id\":1}", recorder.Body.String())
+	assert.NotEmpty(t, recorder.Result().Trailer.Get(wirex.HeaderXStreamError))
+
+	// Before the first item, the error is the response.
+	recorder = serve(httptest.NewRequest(http.MethodGet, "/items", nil), StreamNDJSON(failing(wirex.Error(http.StatusNotFound, errors.New("no such list")))))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(wirex.HeaderTrailer))
+
+	recorder = serve(httptest.NewRequest(http.MethodGet, "/items", nil), StreamNDJSON(failing(errors.New("connection refused"))))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestStreamFlushInterval(t *testing.T) {
+	ch := make(chan item)
+
+	engine := wirex.New()
+	engine.Route("/items").Get(func(r *http.Request) wirex.Writer {
+		return &StreamData[item]{
+			Status:        http.StatusOK,
+			ContentType:   wirex.MIMEApplicationNDJSON,
+			Source:        FromChan(ch),
+			Format:        ndjson[item]{},
+			FlushInterval: 5 * time.Millisecond,
+		}
+	})
+
+	server := httptest.NewServer(engine.Handler())
+	defer server.Close()
+
+	// The headers are sent with the first item.
+	go func() {
+		ch <- item{ID: 1}
+		ch <- item{ID: 2}
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL + "/items")
+	if err != nil {
+		t.Fatalf("Could not make GET request to /items: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The items reach the client while the source is idle, without waiting for the next one.
+	lines := bufio.NewReader(resp.Body)
+
+	for _, expected := range []string{"{\"id\":1}\n", "{\"id\":2}\n"} {
+		line, err := lines.ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+
+	close(ch)
+}
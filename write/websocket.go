@@ -0,0 +1,19 @@
+package write
+
+import (
+	"github.com/bridgex-eu/wirex"
+)
+
+// WebSocket upgrades the request to a WebSocket connection and runs the handler with it, using the
+// default wirex.WebSocketOptions. The connection is closed when the handler returns.
+//
+// Usage Example:
+//
+//	engine.Route("/ws").Get(func(r *http.Request) wirex.Writer {
+//		return write.WebSocket(func(conn *wirex.WSConn) {
+//			conn.WriteJSON(status)
+//		})
+//	})
+func WebSocket(handler func(conn *wirex.WSConn), other ...wirex.HeaderWriter) wirex.Writer {
+	return WithHeader(&wirex.WebSocket{Handler: handler}, other...)
+}
@@ -0,0 +1,18 @@
+package write
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/bridgex-eu/wirex"
+)
+
+// serve routes the request to a handler returning the writer, through an Engine, and records the response.
+func serve(req *http.Request, writer wirex.Writer) *httptest.ResponseRecorder {
+	engine := wirex.New()
+	engine.Route(req.URL.Path).Any(func(r *http.Request) wirex.Writer { return writer })
+
+	recorder := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(recorder, req)
+	return recorder
+}